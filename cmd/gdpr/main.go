@@ -0,0 +1,90 @@
+// Package gdpr handles data subject requests from the command line;
+// run it from the app root - the bucket is ./app-bucket or the cloud bucket;
+//
+//	gdpr -action export    -user 12345 [-survey fmt] [-email a@b.c] > 12345.json
+//	gdpr -action erase     -user 12345 [-survey fmt] [-email a@b.c]
+//	gdpr -action anonymize -days 365
+//
+// Anonymize can be scheduled - i.e. daily.
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/pbberlin/flags"
+	"github.com/zew/go-questionnaire/pkg/gdpr"
+)
+
+func main() {
+
+	log.SetFlags(log.Lshortfile | log.Ldate | log.Ltime)
+
+	fl := flags.New()
+	fl.Add(flags.FlagT{
+		Long:       "action",
+		Short:      "a",
+		DefaultVal: "export",
+		Desc:       "export, erase or anonymize",
+	})
+	fl.Add(flags.FlagT{
+		Long:       "user",
+		Short:      "u",
+		DefaultVal: "",
+		Desc:       "user ID - for export and erase",
+	})
+	fl.Add(flags.FlagT{
+		Long:       "survey",
+		Short:      "s",
+		DefaultVal: "",
+		Desc:       "survey ID - restricts export and erase; empty for all surveys",
+	})
+	fl.Add(flags.FlagT{
+		Long:       "email",
+		Short:      "e",
+		DefaultVal: "",
+		Desc:       "email - for registrations",
+	})
+	fl.Add(flags.FlagT{
+		Long:       "days",
+		Short:      "d",
+		DefaultVal: "365",
+		Desc:       "retention in days - for anonymize",
+	})
+	fl.Gen()
+
+	userID := fl.ByKey("user").Val
+	surveyID := fl.ByKey("survey").Val
+	email := fl.ByKey("email").Val
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "\t")
+
+	switch fl.ByKey("action").Val {
+	case "export":
+		exp, err := gdpr.Export(userID, surveyID, email, time.Now())
+		if err != nil {
+			log.Fatal(err)
+		}
+		enc.Encode(exp)
+	case "erase":
+		rep, err := gdpr.Erase(userID, surveyID, email)
+		if err != nil {
+			log.Fatal(err)
+		}
+		enc.Encode(rep)
+	case "anonymize":
+		days, err := strconv.Atoi(fl.ByKey("days").Val)
+		if err != nil {
+			log.Fatalf("days: %v", err)
+		}
+		if _, err := gdpr.Anonymize(time.Duration(days)*24*time.Hour, time.Now()); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		log.Fatalf("unknown action %q", fl.ByKey("action").Val)
+	}
+}
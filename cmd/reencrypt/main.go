@@ -0,0 +1,34 @@
+// Package reencrypt encrypts existing response files with the current master key;
+// run it from the app root - with CLOUDIO_KEYS or CLOUDIO_KEY_FILE set;
+// plain text files and files of older master keys are rewritten;
+// afterwards, older keys can be removed from the configuration.
+//
+//	CLOUDIO_KEYS=2022b:...,2022a:... reencrypt -prefix responses
+package main
+
+import (
+	"log"
+
+	"github.com/pbberlin/flags"
+	"github.com/zew/go-questionnaire/pkg/cloudio"
+)
+
+func main() {
+
+	log.SetFlags(log.Lshortfile | log.Ldate | log.Ltime)
+
+	fl := flags.New()
+	fl.Add(flags.FlagT{
+		Long:       "prefix",
+		Short:      "p",
+		DefaultVal: "responses",
+		Desc:       "files below prefix are re-encrypted",
+	})
+	fl.Gen()
+
+	cntr, err := cloudio.Reencrypt(fl.ByKey("prefix").Val)
+	if err != nil {
+		log.Fatalf("re-encryption stopped after %v files: %v", cntr, err)
+	}
+	log.Printf("%v files re-encrypted", cntr)
+}
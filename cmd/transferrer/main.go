@@ -40,4 +40,18 @@ func main() {
 	}
 	log.Printf("CSV file saved under: %v", csvPath)
 
+	xlsxPath, err := tf.ProcessQsXLSX(cfgRem, qs, false)
+	if err != nil {
+		log.Printf("error creating XLSX from questionnaires: %v", err)
+		return
+	}
+	log.Printf("XLSX file saved under: %v", xlsxPath)
+
+	longPath, jsonlPath, err := tf.ProcessQsLong(cfgRem, qs)
+	if err != nil {
+		log.Printf("error creating long format from questionnaires: %v", err)
+		return
+	}
+	log.Printf("long format saved under: %v and %v", longPath, jsonlPath)
+
 }
@@ -1,26 +1,25 @@
 // Package updater makes a change to all questionaires in a given directory;
 // can be applied to single origin json - as well as to filled out json files.
-//     updater.exe -dir ../app-bucket/responses/mul.json
-//     updater.exe -dir ../app-bucket/responses/mul/2019-02
-//     updater.exe -dir ../app-bucket/responses/mul/2019-02/23121.json
+// Run it from the app root; dir is a key in the bucket - see cloudio;
+// files are read via cloudio - thus encrypted files are decrypted;
+// changes are saved back in place - see update().
 //
-// The saving to app-bucked via cloudio might fail
-// and the changes might be saved to app-dir/app-bucket/...
+//	updater.exe -dir responses/mul.json
+//	updater.exe -dir responses/mul/2019-02
+//	updater.exe -dir responses/mul/2019-02/23121.json
 package main
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
 	"log"
 	"math/rand"
-	"os"
 	"path"
-	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/pbberlin/flags"
+	"github.com/zew/go-questionnaire/pkg/cloudio"
 	"github.com/zew/go-questionnaire/pkg/qst"
 )
 
@@ -35,8 +34,8 @@ func main() {
 		flags.FlagT{
 			Long:  "directory",
 			Short: "dir",
-			// DefaultVal: "../../app-bucket/responses/downloaded/fmt/2021-04/11499.json",
-			DefaultVal: "../../app-bucket/responses/downloaded/fmt/2021-04",
+			// DefaultVal: "responses/downloaded/fmt/2021-04/11499.json",
+			DefaultVal: "responses/downloaded/fmt/2021-04",
 			Desc:       "filename - or directory or to iterate",
 		},
 	)
@@ -44,39 +43,29 @@ func main() {
 	dirSrc := fl.ByKey("dir").Val
 
 	//
-	isFile := false
-	files, err := ioutil.ReadDir(dirSrc)
-	if err != nil {
-		log.Printf("Opening as directory failed: %v", err)
-		f, err := os.OpenFile(dirSrc, 0, 0777)
+	files := []string{}
+	if strings.HasSuffix(dirSrc, ".json") {
+		files = append(files, dirSrc)
+	} else {
+		objs, err := cloudio.ReadDir(dirSrc)
 		if err != nil {
-			log.Printf("Opening as file      failed: %v", err)
+			log.Printf("Opening as directory failed: %v", err)
 			return
 		}
-		fi, err := f.Stat()
-		if err != nil {
-			log.Printf("Error obtaining file info: %v", err)
-			return
+		for _, obj := range *objs {
+			if obj.IsDir || !strings.HasSuffix(obj.Key, ".json") {
+				continue // subdirs and .attrs
+			}
+			files = append(files, strings.ReplaceAll(obj.Key, "\\", "/"))
 		}
-		log.Printf("Opening as file succeeded: %v", dirSrc)
-		isFile = true
-		files = append(files, fi)
 	}
 
 	//
 	//
-	fCounter := 0
 	w := &strings.Builder{}
-	for _, f := range files {
-		if strings.HasSuffix(f.Name(), ".attrs") {
-			continue
-		}
-		if f.IsDir() {
-			continue // subdirs...
-		}
-		fCounter++
-		fmt.Fprintf(w, "%3v: %v;  ", fCounter, f.Name())
-		if fCounter%5 == 0 && fCounter != 0 {
+	for i, f := range files {
+		fmt.Fprintf(w, "%3v: %v;  ", i+1, path.Base(f))
+		if (i+1)%5 == 0 {
 			fmt.Fprintf(w, "\n")
 		}
 	}
@@ -85,86 +74,89 @@ func main() {
 	//
 	//
 	cntrChanged := 0
-	for i, f := range files {
+	for i, pSrc := range files {
 
-		if f.Name() != "10210.json" && f.Name() != "10035.json" {
+		fName := path.Base(pSrc)
+		if fName != "10210.json" && fName != "10035.json" {
 			continue
 		}
 
-		if f.IsDir() {
-			continue // subdirs...
-		}
-
-		pSrc := path.Join(dirSrc, f.Name())
-		if isFile {
-			pSrc = filepath.Join(dirSrc)
-		}
 		log.Printf("%3v: opening file  %v", i, pSrc)
-
-		pDst := path.Join(path.Dir(dirSrc), "updated", f.Name())
-
-		bts, err := os.ReadFile(pSrc)
+		changed, err := update(pSrc, change)
 		if err != nil {
-			log.Printf("%3v: Error reading file %v: %v", i, pSrc, err)
-			return
+			log.Printf("%3v: Error updating %v: %v", i, pSrc, err)
+			continue
 		}
-
-		q := qst.QuestionnaireT{}
-		err = json.Unmarshal(bts, &q)
-		if err != nil {
-			log.Printf("%3v: Error unmarshalling file %v: %v", i, pSrc, err)
-			return
+		if changed {
+			cntrChanged++
+			log.Printf("%3v: questionnaire %v saved", i, pSrc)
 		}
-		log.Printf("%3v: questionnaire %v - unmarshalled - %10.3f MB", i, pSrc, float64(len(bts)/(1<<10))/(1<<10))
 
-		//
-		//
-		//
-		// now we might perform various changes to the questionnaire
-		// then saving the questionnaire; checksum via q.Save()
+	}
+	log.Printf("================")
+	log.Printf("Finish - %v changes", cntrChanged)
+
+}
 
-		var t1 time.Time
-		q.ClosingTime = t1
-		err = q.Save1(pDst)
+// maxAttempts to update a questionnaire,
+// which participants are saving concurrently
+const maxAttempts = 3
+
+// update loads key, applies change and saves the result back to key;
+// if the questionnaire was saved meanwhile - i.e. by a participant -
+// it is reloaded and change is applied again;
+// the incremented revision makes sessions of the participant merge onto the update -
+// see qst.Save1Versioned()
+func update(key string, change func(q *qst.QuestionnaireT) bool) (bool, error) {
+	for attempt := 1; ; attempt++ {
+		q, err := qst.Load1(key)
 		if err != nil {
-			log.Printf("%3v: Error saving %v: %v", i, pSrc, err)
+			return false, err
 		}
-		cntrChanged++
-
-		if false {
-			if q.ShufflingVariations > 0 {
-				log.Printf("%3v: questionnaire %v - correction needed %v", i, pSrc, q.Survey.Deadline)
-				// q.Survey.Deadline = tInstead
-				q.ShufflingVariations = 0
-				err := q.Save1(pDst)
-				if err != nil {
-					log.Printf("%3v: Error saving %v: %v", i, pSrc, err)
-				}
-				cntrChanged++
-				log.Printf("%3v: questionnaire %v saved", i, pSrc)
-			}
+		if !change(q) {
+			return false, nil
+		}
+		err = q.Save1Versioned(key)
+		if errors.Is(err, qst.ErrStale) && attempt < maxAttempts {
+			log.Printf("%v was saved meanwhile - reloading: %v", key, err)
+			continue
 		}
+		return err == nil, err
+	}
+}
 
-		if false {
-			search := q.Pages[1].Groups[8].Inputs[0].Label["fr"]
-			old := "con-trainte"
-			new := "contrainte"
-			if strings.Contains(search, old) {
-				replaced := strings.Replace(search, old, new, -1)
-				q.Pages[1].Groups[8].Inputs[0].Label["fr"] = replaced
-				err := q.Save1(pDst)
-				if err != nil {
-					log.Printf("%3v: Error saving %v: %v", i, pSrc, err)
-				}
-				cntrChanged++
-				log.Printf("%3v: questionnaire %v - %v corrected to %v", i, pSrc, old, new)
-			} else {
-				log.Printf("%3v: questionnaire %v - correction not needed %v", i, pSrc, search)
-			}
+// change performs various changes to the questionnaire;
+// returns false, if no change is needed
+func change(q *qst.QuestionnaireT) bool {
+
+	var t1 time.Time
+	q.ClosingTime = t1
+	changed := true
+
+	if false {
+		changed = false
+		if q.ShufflingVariations > 0 {
+			log.Printf("questionnaire %v - correction needed %v", q.UserID, q.Survey.Deadline)
+			// q.Survey.Deadline = tInstead
+			q.ShufflingVariations = 0
+			changed = true
 		}
+	}
 
+	if false {
+		changed = false
+		search := q.Pages[1].Groups[8].Inputs[0].Label["fr"]
+		old := "con-trainte"
+		new := "contrainte"
+		if strings.Contains(search, old) {
+			replaced := strings.Replace(search, old, new, -1)
+			q.Pages[1].Groups[8].Inputs[0].Label["fr"] = replaced
+			changed = true
+			log.Printf("questionnaire %v - %v corrected to %v", q.UserID, old, new)
+		} else {
+			log.Printf("questionnaire %v - correction not needed %v", q.UserID, search)
+		}
 	}
-	log.Printf("================")
-	log.Printf("Finish - %v changes", cntrChanged)
 
+	return changed
 }
@@ -1 +1 @@
-./updater.exe -dir ../app-bucket/responses/lt2020/2020-05
+./updater.exe -dir responses/lt2020/2020-05
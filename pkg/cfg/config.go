@@ -86,6 +86,10 @@ type ConfigT struct {
 	Profiles          map[string]map[string]string `json:"profiles"`                      // Profiles are sets of attributes, selected by the `p` parameter at login, containing key-values which are copied into the logged in user's attributes
 	DirectLoginRanges []directLoginRangeT          `json:"direct_login_ranges,omitempty"` // DirectLoginRanges - user id to language preselection for direct login
 
+	PanelProviders map[string]PanelProviderT `json:"panel_providers,omitempty"` // PanelProviders by survey ID - inbound params and redirects back to the provider
+
+	RetentionDays int `json:"retention_days,omitempty"` // RetentionDays after closing, RemoteIP and UserAgent are anonymized - see pkg/gdpr; 0 disables
+
 }
 
 // CfgPath is obtained by ENV variable or command line flag in main package.
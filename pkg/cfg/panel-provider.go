@@ -0,0 +1,84 @@
+package cfg
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"regexp"
+)
+
+// PanelProviderT configures an external panel provider delivering participants to a survey;
+// participants arrive with the provider's IDs in the Inbound URL params;
+// on closing the questionnaire, they are redirected back according to their end state
+type PanelProviderT struct {
+	Inbound   []string          `json:"inbound"`              // URL params copied into the login attributes - exempted from the hash check; i.e. "psid"
+	IDParam   string            `json:"id_param"`             // one of Inbound - holding the provider's participant ID - appended to the redirect URL
+	Redirects map[string]string `json:"redirects"`            // end state => URL; i.e. "complete": "https://panel.example.com/return?status=1"
+	Secret    string            `json:"secret,omitempty"`     // if set, redirect URLs are signed with HMAC-SHA256
+	SignParam string            `json:"sign_param,omitempty"` // name of the signature param; default "sig"
+}
+
+// IsInbound is true for URL params to be captured
+func (p PanelProviderT) IsInbound(key string) bool {
+	for _, in := range p.Inbound {
+		if in == key {
+			return true
+		}
+	}
+	return false
+}
+
+// inboundVal restricts inbound param values to URL unreserved characters
+var inboundVal = regexp.MustCompile(`^[A-Za-z0-9._~-]{1,128}$`)
+
+// ValidInbound is true for values of inbound params,
+// which are safe to store unescaped - and to send back in the redirect URL
+func ValidInbound(val string) bool {
+	return inboundVal.MatchString(val)
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of s
+func (p PanelProviderT) Sign(s string) string {
+	mac := hmac.New(sha256.New, []byte(p.Secret))
+	mac.Write([]byte(s))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// RedirectURL returns the provider URL for end state;
+// the participant's provider ID is taken from attrs;
+// the signature is computed over the complete URL before it
+// and is appended as last param;
+// empty, if no URL is configured for end state
+func (p PanelProviderT) RedirectURL(endState string, attrs map[string]string) (string, error) {
+
+	base, ok := p.Redirects[endState]
+	if !ok || base == "" {
+		return "", nil
+	}
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("panel provider redirect for %v: %w", endState, err)
+	}
+
+	vals := u.Query()
+	if p.IDParam != "" {
+		vals.Set(p.IDParam, attrs[p.IDParam])
+	}
+	u.RawQuery = vals.Encode()
+	ret := u.String()
+
+	if p.Secret != "" {
+		sp := p.SignParam
+		if sp == "" {
+			sp = "sig"
+		}
+		sep := "?"
+		if u.RawQuery != "" {
+			sep = "&"
+		}
+		ret += sep + sp + "=" + p.Sign(ret)
+	}
+	return ret, nil
+}
@@ -0,0 +1,69 @@
+package cfg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPanelProviderT_RedirectURL(t *testing.T) {
+
+	pp := PanelProviderT{
+		Inbound: []string{"psid", "src"},
+		IDParam: "psid",
+		Redirects: map[string]string{
+			"complete":     "https://panel.example.com/return?status=1",
+			"screened_out": "https://panel.example.com/return?status=2",
+		},
+	}
+	attrs := map[string]string{"psid": "ab 12", "src": "x"}
+
+	tts := []struct {
+		state  string
+		secret string
+		want   string
+	}{
+		{"complete", "", "https://panel.example.com/return?psid=ab+12&status=1"},
+		{"screened_out", "", "https://panel.example.com/return?psid=ab+12&status=2"},
+		{"over_quota", "", ""},
+		{"complete", "s3cret", "https://panel.example.com/return?psid=ab+12&status=1&sig="},
+	}
+
+	for idx, tt := range tts {
+		pp.Secret = tt.secret
+		got, err := pp.RedirectURL(tt.state, attrs)
+		if err != nil {
+			t.Errorf("idx%v: %v", idx, err)
+			continue
+		}
+		if tt.secret == "" {
+			if got != tt.want {
+				t.Errorf("idx%v: got %v - want %v", idx, got, tt.want)
+			}
+			continue
+		}
+		if !strings.HasPrefix(got, tt.want) {
+			t.Errorf("idx%v: got %v - want prefix %v", idx, got, tt.want)
+			continue
+		}
+		unsigned := strings.TrimSuffix(tt.want, "&sig=")
+		if sig := strings.TrimPrefix(got, tt.want); sig != pp.Sign(unsigned) {
+			t.Errorf("idx%v: signature %v does not verify", idx, sig)
+		}
+	}
+
+	if !pp.IsInbound("src") || pp.IsInbound("u") {
+		t.Errorf("IsInbound wrong")
+	}
+
+	for val, want := range map[string]bool{
+		"ab-12_x.Y~": true,
+		"":           false,
+		"a&b=c":      false,
+		"<script>":   false,
+		"ab 12":      false,
+	} {
+		if ValidInbound(val) != want {
+			t.Errorf("ValidInbound(%q) should be %v", val, want)
+		}
+	}
+}
@@ -0,0 +1,385 @@
+// Package chart renders bar charts, stacked bars, histograms and line charts
+// as inline SVG - without client side JavaScript;
+// for dynamic textblocks, admin pages and static reports;
+// labels are multi-language trl.S - rendered for a given language code;
+// numbers are formatted with decimal comma, where the language requires it.
+package chart
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/zew/go-questionnaire/pkg/trl"
+)
+
+// Palette is used for series without explicit color
+var Palette = []string{
+	"#4e79a7", "#f28e2b", "#59a14f", "#e15759",
+	"#76b7b2", "#edc948", "#b07aa1", "#9c755f",
+}
+
+// SeriesT is one row of values - one per category
+type SeriesT struct {
+	Name   trl.S
+	Values []float64
+	Color  string // CSS color; default from Palette
+}
+
+// ChartT contains data and labels;
+// Categories are the x-axis labels - i.e. answer options or wave IDs
+type ChartT struct {
+	Title      trl.S
+	XLabel     trl.S
+	YLabel     trl.S
+	Categories []trl.S
+	Series     []SeriesT
+
+	Width  int // default 480
+	Height int // default 280
+
+	Decimals int // of y-axis tick labels
+}
+
+// margins around the plot area
+const (
+	marginLeft   = 56
+	marginRight  = 12
+	marginTop    = 28
+	marginBottom = 44
+	legendHeight = 20
+)
+
+func (c *ChartT) defaults() {
+	if c.Width == 0 {
+		c.Width = 480
+	}
+	if c.Height == 0 {
+		c.Height = 280
+	}
+}
+
+func (c *ChartT) color(idx int) string {
+	if c.Series[idx].Color != "" {
+		return c.Series[idx].Color
+	}
+	return Palette[idx%len(Palette)]
+}
+
+// NiceScale returns axis bounds and tick step
+// enclosing min and max with round numbers
+func NiceScale(min, max float64, maxTicks int) (lo, hi, step float64) {
+	if min > max {
+		min, max = max, min
+	}
+	if min == max {
+		if min == 0 {
+			return 0, 1, 0.2
+		}
+		min, max = min-math.Abs(min)/2, max+math.Abs(max)/2
+	}
+	rng := niceNum(max-min, false)
+	step = niceNum(rng/float64(maxTicks-1), true)
+	lo = math.Floor(min/step) * step
+	hi = math.Ceil(max/step) * step
+	return lo, hi, step
+}
+
+// niceNum rounds x to 1, 2, 5 times a power of ten
+func niceNum(x float64, round bool) float64 {
+	exp := math.Floor(math.Log10(x))
+	f := x / math.Pow(10, exp)
+	var nf float64
+	switch {
+	case round && f < 1.5:
+		nf = 1
+	case round && f < 3:
+		nf = 2
+	case round && f < 7:
+		nf = 5
+	case round:
+		nf = 10
+	case f <= 1:
+		nf = 1
+	case f <= 2:
+		nf = 2
+	case f <= 5:
+		nf = 5
+	default:
+		nf = 10
+	}
+	return nf * math.Pow(10, exp)
+}
+
+// canvasT holds the computed geometry of one chart
+type canvasT struct {
+	c      *ChartT
+	lc     string
+	b      *strings.Builder
+	x0, y0 float64 // bottom left of plot area
+	w, h   float64 // plot area
+	lo, hi float64 // y-axis bounds
+}
+
+func newCanvas(c *ChartT, lc string, min, max float64) *canvasT {
+	c.defaults()
+	cv := &canvasT{c: c, lc: lc, b: &strings.Builder{}}
+	bottom := float64(marginBottom)
+	if len(c.Series) > 1 {
+		bottom += legendHeight
+	}
+	cv.x0 = marginLeft
+	cv.y0 = float64(c.Height) - bottom
+	cv.w = float64(c.Width - marginLeft - marginRight)
+	cv.h = cv.y0 - marginTop
+	if min > 0 {
+		min = 0 // bars and counts start at zero
+	}
+	lo, hi, step := NiceScale(min, max, 6)
+	cv.lo, cv.hi = lo, hi
+
+	fmt.Fprintf(cv.b,
+		"<svg xmlns='http://www.w3.org/2000/svg' class='chart' width='%v' height='%v' viewBox='0 0 %v %v' font-family='sans-serif' font-size='11'>\n",
+		c.Width, c.Height, c.Width, c.Height,
+	)
+	if t := c.Title.TrSilent(lc); t != "" {
+		fmt.Fprintf(cv.b, "<text x='%v' y='16' text-anchor='middle' font-size='13' font-weight='bold'>%v</text>\n", c.Width/2, esc(t))
+	}
+
+	// y-axis - grid lines and tick labels
+	for v := lo; v <= hi+step/2; v += step {
+		y := cv.y(v)
+		fmt.Fprintf(cv.b, "<line x1='%.1f' y1='%.1f' x2='%.1f' y2='%.1f' stroke='#ddd'/>\n", cv.x0, y, cv.x0+cv.w, y)
+		fmt.Fprintf(cv.b, "<text x='%.1f' y='%.1f' text-anchor='end' dominant-baseline='middle'>%v</text>\n", cv.x0-4, y, Num(lc, c.Decimals, v))
+	}
+	fmt.Fprintf(cv.b, "<line x1='%.1f' y1='%.1f' x2='%.1f' y2='%.1f' stroke='#333'/>\n", cv.x0, cv.y(0), cv.x0+cv.w, cv.y(0))
+	if t := c.YLabel.TrSilent(lc); t != "" {
+		fmt.Fprintf(cv.b, "<text transform='translate(12,%.1f) rotate(-90)' text-anchor='middle'>%v</text>\n", marginTop+cv.h/2, esc(t))
+	}
+	if t := c.XLabel.TrSilent(lc); t != "" {
+		fmt.Fprintf(cv.b, "<text x='%.1f' y='%.1f' text-anchor='middle'>%v</text>\n", cv.x0+cv.w/2, cv.y0+36, esc(t))
+	}
+	return cv
+}
+
+// y converts a value into the vertical pixel position
+func (cv *canvasT) y(v float64) float64 {
+	if cv.hi == cv.lo {
+		return cv.y0
+	}
+	return cv.y0 - (v-cv.lo)/(cv.hi-cv.lo)*cv.h
+}
+
+// categories writes the x-axis labels - centered in slots
+func (cv *canvasT) categories() float64 {
+	n := len(cv.c.Categories)
+	if n == 0 {
+		return cv.w
+	}
+	slot := cv.w / float64(n)
+	for i, cat := range cv.c.Categories {
+		x := cv.x0 + slot*(float64(i)+0.5)
+		fmt.Fprintf(cv.b, "<text x='%.1f' y='%.1f' text-anchor='middle'>%v</text>\n", x, cv.y0+14, esc(cat.TrSilent(cv.lc)))
+	}
+	return slot
+}
+
+// legend is written only for several series
+func (cv *canvasT) legend() {
+	if len(cv.c.Series) < 2 {
+		return
+	}
+	x := cv.x0
+	y := float64(cv.c.Height) - 8
+	for i, s := range cv.c.Series {
+		name := s.Name.TrSilent(cv.lc)
+		fmt.Fprintf(cv.b, "<rect x='%.1f' y='%.1f' width='10' height='10' fill='%v'/>\n", x, y-9, cv.c.color(i))
+		fmt.Fprintf(cv.b, "<text x='%.1f' y='%.1f'>%v</text>\n", x+14, y, esc(name))
+		x += 24 + 6.5*float64(len([]rune(name)))
+	}
+}
+
+func (cv *canvasT) String() string {
+	cv.legend()
+	fmt.Fprint(cv.b, "</svg>\n")
+	return cv.b.String()
+}
+
+// decimalComma lists languages writing 1,5 instead of 1.5
+var decimalComma = map[string]bool{"de": true, "es": true, "fr": true, "it": true, "pl": true}
+
+// Num formats v with dec decimals - -1 for the shortest representation;
+// with decimal comma for lc in decimalComma
+func Num(lc string, dec int, v float64) string {
+	s := strconv.FormatFloat(v, 'f', dec, 64)
+	if decimalComma[lc] {
+		s = strings.Replace(s, ".", ",", 1)
+	}
+	return s
+}
+
+func esc(s string) string {
+	return html.EscapeString(s)
+}
+
+// minMax over all series; NaN values are missing values
+func (c *ChartT) minMax() (float64, float64) {
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, s := range c.Series {
+		for _, v := range s.Values {
+			if math.IsNaN(v) {
+				continue
+			}
+			min = math.Min(min, v)
+			max = math.Max(max, v)
+		}
+	}
+	if math.IsInf(min, 0) {
+		return 0, 0
+	}
+	return min, max
+}
+
+// Bar renders grouped bars - one group per category, one bar per series
+func Bar(c ChartT, lc string) string {
+	min, max := c.minMax()
+	cv := newCanvas(&c, lc, min, max)
+	slot := cv.categories()
+	if len(c.Series) == 0 {
+		return cv.String()
+	}
+	barW := slot * 0.8 / float64(len(c.Series))
+	for iS, s := range c.Series {
+		for iC, v := range s.Values {
+			x := cv.x0 + slot*float64(iC) + slot*0.1 + barW*float64(iS)
+			y1, y2 := cv.y(v), cv.y(0)
+			if y1 > y2 {
+				y1, y2 = y2, y1
+			}
+			fmt.Fprintf(cv.b, "<rect x='%.1f' y='%.1f' width='%.1f' height='%.1f' fill='%v'><title>%v</title></rect>\n",
+				x, y1, barW, y2-y1, c.color(iS), Num(lc, -1, v))
+		}
+	}
+	return cv.String()
+}
+
+// StackedBar renders one bar per category - series stacked on top of each other;
+// negative values are skipped
+func StackedBar(c ChartT, lc string) string {
+	max := 0.0
+	for iC := range c.Categories {
+		sum := 0.0
+		for _, s := range c.Series {
+			if iC < len(s.Values) {
+				sum += s.Values[iC]
+			}
+		}
+		max = math.Max(max, sum)
+	}
+	cv := newCanvas(&c, lc, 0, max)
+	slot := cv.categories()
+	for iC := range c.Categories {
+		base := 0.0
+		for iS, s := range c.Series {
+			if iC >= len(s.Values) {
+				continue
+			}
+			v := s.Values[iC]
+			if v <= 0 {
+				continue
+			}
+			y1, y2 := cv.y(base+v), cv.y(base)
+			fmt.Fprintf(cv.b, "<rect x='%.1f' y='%.1f' width='%.1f' height='%.1f' fill='%v'><title>%v</title></rect>\n",
+				cv.x0+slot*float64(iC)+slot*0.15, y1, slot*0.7, y2-y1, c.color(iS), Num(lc, -1, v))
+			base += v
+		}
+	}
+	return cv.String()
+}
+
+// Line renders one polyline per series;
+// categories are the points in time - i.e. wave IDs;
+// NaN values are missing values - they interrupt the line
+func Line(c ChartT, lc string) string {
+	min, max := c.minMax()
+	cv := newCanvas(&c, lc, min, max)
+	slot := cv.categories()
+	for iS, s := range c.Series {
+		segments := [][]string{}
+		pts := []string{}
+		for iC, v := range s.Values {
+			if math.IsNaN(v) {
+				if len(pts) > 0 {
+					segments = append(segments, pts)
+				}
+				pts = []string{}
+				continue
+			}
+			pts = append(pts, fmt.Sprintf("%.1f,%.1f", cv.x0+slot*(float64(iC)+0.5), cv.y(v)))
+		}
+		if len(pts) > 0 {
+			segments = append(segments, pts)
+		}
+		for _, seg := range segments {
+			fmt.Fprintf(cv.b, "<polyline points='%v' fill='none' stroke='%v' stroke-width='2'/>\n", strings.Join(seg, " "), c.color(iS))
+			for _, pt := range seg {
+				xy := strings.Split(pt, ",")
+				fmt.Fprintf(cv.b, "<circle cx='%v' cy='%v' r='3' fill='%v'/>\n", xy[0], xy[1], c.color(iS))
+			}
+		}
+	}
+	return cv.String()
+}
+
+// Histogram counts values into bins of equal width
+// and renders them as bars; Categories and Series of c are overwritten
+func Histogram(c ChartT, lc string, values []float64, bins int) string {
+	counts, edges := Bins(values, bins)
+	c.Categories = make([]trl.S, len(counts))
+	vals := make([]float64, len(counts))
+	for i := range counts {
+		lbl := Num(lc, c.Decimals, edges[i])
+		c.Categories[i] = trl.S{lc: lbl}
+		vals[i] = float64(counts[i])
+	}
+	c.Series = []SeriesT{{Values: vals}}
+	dec := c.Decimals
+	c.Decimals = 0 // counts
+	svg := Bar(c, lc)
+	c.Decimals = dec
+	return svg
+}
+
+// Bins returns counts and lower bin edges
+func Bins(values []float64, bins int) ([]int, []float64) {
+	if bins < 1 {
+		bins = 10
+	}
+	if len(values) == 0 {
+		return []int{}, []float64{}
+	}
+	min, max := values[0], values[0]
+	for _, v := range values {
+		min = math.Min(min, v)
+		max = math.Max(max, v)
+	}
+	width := (max - min) / float64(bins)
+	if width == 0 {
+		width = 1
+	}
+	counts := make([]int, bins)
+	edges := make([]float64, bins)
+	for i := range edges {
+		edges[i] = min + width*float64(i)
+	}
+	for _, v := range values {
+		idx := int((v - min) / width)
+		if idx >= bins {
+			idx = bins - 1 // max belongs to the last bin
+		}
+		counts[idx]++
+	}
+	return counts, edges
+}
@@ -0,0 +1,112 @@
+package chart
+
+import (
+	"encoding/xml"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zew/go-questionnaire/pkg/trl"
+)
+
+func TestNiceScale(t *testing.T) {
+	tests := []struct {
+		min, max     float64
+		lo, hi, step float64
+	}{
+		{0, 9.3, 0, 10, 2},
+		{0, 1, 0, 1, 0.2},
+		{-3, 47, -10, 50, 10},
+		{0, 0, 0, 1, 0.2},
+	}
+	for _, tt := range tests {
+		lo, hi, step := NiceScale(tt.min, tt.max, 6)
+		if lo != tt.lo || hi != tt.hi || step != tt.step {
+			t.Errorf("NiceScale(%v,%v) = %v,%v,%v - want %v,%v,%v", tt.min, tt.max, lo, hi, step, tt.lo, tt.hi, tt.step)
+		}
+	}
+}
+
+func TestBins(t *testing.T) {
+	counts, edges := Bins([]float64{0, 1, 2, 3, 4, 10}, 5)
+	if want := []int{2, 2, 1, 0, 1}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("counts %v - want %v", counts, want)
+	}
+	if want := []float64{0, 2, 4, 6, 8}; !reflect.DeepEqual(edges, want) {
+		t.Errorf("edges %v - want %v", edges, want)
+	}
+}
+
+// all chart types must yield well formed XML
+func TestWellFormed(t *testing.T) {
+	c := ChartT{
+		Title:      trl.S{"en": "Growth <GDP> & more", "de": "Wachstum"},
+		YLabel:     trl.S{"en": "percent"},
+		Categories: []trl.S{{"en": "2022-01"}, {"en": "2022-02"}, {"en": "2022-03"}},
+		Series: []SeriesT{
+			{Name: trl.S{"en": "Germany"}, Values: []float64{1, 2.5, -1}},
+			{Name: trl.S{"en": "Euro area"}, Values: []float64{0.5, 1, 2}},
+		},
+	}
+	svgs := map[string]string{
+		"bar":       Bar(c, "en"),
+		"stacked":   StackedBar(c, "de"),
+		"line":      Line(c, "en"),
+		"histogram": Histogram(c, "en", []float64{1, 2, 2, 3, 7}, 4),
+	}
+	for name, svg := range svgs {
+		dec := xml.NewDecoder(strings.NewReader(svg))
+		for {
+			_, err := dec.Token()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Errorf("%v: %v", name, err)
+				break
+			}
+		}
+	}
+}
+
+func TestNum(t *testing.T) {
+	tests := []struct {
+		lc   string
+		dec  int
+		v    float64
+		want string
+	}{
+		{"en", 1, 2.5, "2.5"},
+		{"de", 1, 2.5, "2,5"},
+		{"de", 0, 1000, "1000"},
+		{"de", -1, 0.25, "0,25"},
+	}
+	for _, tt := range tests {
+		if got := Num(tt.lc, tt.dec, tt.v); got != tt.want {
+			t.Errorf("Num(%v, %v, %v) = %v - want %v", tt.lc, tt.dec, tt.v, got, tt.want)
+		}
+	}
+	if svg := Histogram(ChartT{Decimals: 1}, "de", []float64{0.5, 1, 2}, 2); !strings.Contains(svg, ">1,2<") {
+		t.Errorf("German histogram should have decimal commas:\n%v", svg)
+	}
+}
+
+// missing values interrupt the line - and do not distort the scale
+func TestLineNaN(t *testing.T) {
+	c := ChartT{
+		Categories: []trl.S{{"en": "1"}, {"en": "2"}, {"en": "3"}, {"en": "4"}},
+		Series:     []SeriesT{{Values: []float64{1, 2, math.NaN(), 4}}},
+	}
+	if min, max := c.minMax(); min != 1 || max != 4 {
+		t.Errorf("minMax = %v, %v - want 1, 4", min, max)
+	}
+	svg := Line(c, "en")
+	if n := strings.Count(svg, "<polyline"); n != 2 {
+		t.Errorf("want 2 line segments - got %v", n)
+	}
+	if strings.Contains(svg, "NaN") {
+		t.Errorf("NaN in output")
+	}
+}
@@ -4,6 +4,14 @@
 // It is zero config; either saving to local ./app-bucket/
 // or to appenginge bucket <appID>, depending on environment variables.
 //
+// Environment variable CLOUDIO_BUCKET_URL overrides both;
+// it contains a gocloud.dev bucket URL:
+//    file:///var/app-bucket
+//    gs://my-bucket
+//    s3://my-bucket?region=eu-central-1
+//    s3://my-bucket?endpoint=localhost:9000&disableSSL=true&s3ForcePathStyle=true&region=us-east-1  (MinIO)
+//    azblob://my-container  (AZURE_STORAGE_ACCOUNT and AZURE_STORAGE_KEY)
+//
 // The zero configuration is important, cause we load the *actual* configuration file
 // with this package, and want to avoid circular trouble or bootstrap hell.
 //
@@ -34,12 +42,16 @@ import (
 
 	"cloud.google.com/go/storage"
 	"gocloud.dev/blob"
+	_ "gocloud.dev/blob/azureblob"
 	_ "gocloud.dev/blob/fileblob" // local file system
 	_ "gocloud.dev/blob/gcsblob"
+	_ "gocloud.dev/blob/s3blob" // AWS S3 and compatible, i.e. MinIO
 )
 
 var appsID string // Google app engine ID
 
+var bucketURL string // explicit bucket URL - overriding appsID and local
+
 // if executable is run in ./cmd/server
 // var exeToAppRoot = path.Join("..", "..")
 // if executable is in app root then
@@ -54,6 +66,10 @@ func init() {
 			appsID = tokens[1]
 		}
 	}
+	bucketURL = strings.TrimSpace(os.Getenv("CLOUDIO_BUCKET_URL"))
+	if bucketURL != "" {
+		log.Printf("cloudio: bucket %v", bucketURL)
+	}
 }
 
 func prepareLocalDir() error {
@@ -102,7 +118,19 @@ func bucketGoogle() (*blob.Bucket, error) {
 	return bucket, nil
 }
 
+// bucketByURL opens the bucket from CLOUDIO_BUCKET_URL
+func bucketByURL() (*blob.Bucket, error) {
+	bucket, err := blob.OpenBucket(context.Background(), bucketURL)
+	if err != nil {
+		return nil, fmt.Errorf("could not open bucket for %v: %v", bucketURL, err)
+	}
+	return bucket, nil
+}
+
 func bucket() (*blob.Bucket, error) {
+	if bucketURL != "" {
+		return bucketByURL()
+	}
 	if appsID != "" {
 		return bucketGoogle()
 	}
@@ -145,12 +173,33 @@ func Attrs(fpth string) (attrs *blob.Attributes, err error) {
 // Permissions of parameter perm are not implemented.
 //
 // No memory allocation. intf is streamed into blob.
+// Except for encrypted files - see encrypt.go.
+//
+// Local files are written to a temp file and renamed;
+// failed writes leave the previous file untouched.
 func WriteFile(fileName string, r io.Reader, perm os.FileMode) (err error) {
+	return writeFile(fileName, r, nil)
+}
 
-	ctx := context.Background()
+func writeFile(fileName string, r io.Reader, opts *blob.WriterOptions) (err error) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 	var buck *blob.Bucket
 	var errSec error
 
+	if encrypted(fileName) {
+		plain, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		enc, err := encrypt(plain)
+		if err != nil {
+			return fmt.Errorf("encrypting %v: %w", fileName, err)
+		}
+		r = bytes.NewReader(enc)
+	}
+
 	// Bucket / directory
 	buck, err = bucket()
 	if err != nil {
@@ -166,7 +215,7 @@ func WriteFile(fileName string, r io.Reader, perm os.FileMode) (err error) {
 	}()
 
 	// Writer to "filename" in bucket
-	w, err := buck.NewWriter(ctx, fileName, nil)
+	w, err := buck.NewWriter(ctx, fileName, opts)
 	if err != nil {
 		log.Printf("Error opening writer to bucket: %v", err)
 		return
@@ -174,7 +223,11 @@ func WriteFile(fileName string, r io.Reader, perm os.FileMode) (err error) {
 	defer func() {
 		errSec = w.Close()
 		if errSec != nil {
-			err = combiErr{err, errSec}
+			if err == nil {
+				err = errSec // i.e. failed precondition
+			} else {
+				err = combiErr{err, errSec}
+			}
 			log.Printf("Error closing writer to bucket: %v", errSec)
 		}
 	}()
@@ -182,6 +235,7 @@ func WriteFile(fileName string, r io.Reader, perm os.FileMode) (err error) {
 	// Writing bytes to writer to bucket
 	_, err = io.Copy(w, r) // most memory efficient
 	if err != nil {
+		cancel() // discard the partial write - instead of renaming it
 		log.Printf("Error writing to bucket %v: %v", fileName, err)
 	}
 
@@ -190,10 +244,30 @@ func WriteFile(fileName string, r io.Reader, perm os.FileMode) (err error) {
 
 // ReadFile is the cousin of os.ReadFile
 // Memory allocation for the file contents.
+// Encrypted files are decrypted - see encrypt.go.
 //
 // Open() is another version returning a reader
 // but also an io.ReadCloser and an io.Closer - for bucket and reader.
 func ReadFile(fileName string) (bts []byte, err error) {
+	bts, err = readRaw(fileName)
+	if err != nil || !IsEncrypted(bts) {
+		return
+	}
+	bts, _, err = decrypt(bts)
+	if err != nil {
+		err = fmt.Errorf("decrypting %v: %w", fileName, err)
+	}
+	return
+}
+
+// readRaw reads the file contents - without decryption
+func readRaw(fileName string) (bts []byte, err error) {
+	bts, _, err = readRawGen(fileName)
+	return
+}
+
+// readRawGen also returns the generation of the file - see ReadFileGen()
+func readRawGen(fileName string) (bts []byte, gen int64, err error) {
 
 	ctx := context.Background()
 	var buck *blob.Bucket
@@ -238,6 +312,11 @@ func ReadFile(fileName string) (bts []byte, err error) {
 		log.Printf("Error reading from reader from bucket: %v", errSec)
 	}
 	bts = buf.Bytes()
+
+	var sr *storage.Reader
+	if r.As(&sr) {
+		gen = sr.Attrs.Generation
+	}
 	return
 
 }
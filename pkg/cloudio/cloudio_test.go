@@ -0,0 +1,108 @@
+package cloudio
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBucketURL(t *testing.T) {
+
+	dir := t.TempDir()
+	orig := bucketURL
+	defer func() { bucketURL = orig }()
+	bucketURL = "file:///" + filepath.ToSlash(dir)
+
+	err := WriteFile("responses/test/1234.json", strings.NewReader(`{"user_id":"1234"}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "responses", "test", "1234.json")); err != nil {
+		t.Errorf("file not written to bucket URL: %v", err)
+	}
+	bts, err := ReadFile("responses/test/1234.json")
+	if err != nil || !bytes.Contains(bts, []byte("1234")) {
+		t.Errorf("reading back: %v - %s", err, bts)
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read(p []byte) (int, error) {
+	copy(p, "partial")
+	return len("partial"), errors.New("connection reset")
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+
+	dir := t.TempDir()
+	orig := bucketURL
+	defer func() { bucketURL = orig }()
+	bucketURL = "file:///" + filepath.ToSlash(dir)
+
+	fn := "responses/test/1234.json"
+	if _, _, err := ReadFileGen(fn); err == nil || !IsNotExist(err) {
+		t.Errorf("want not exist - got %v", err)
+	}
+	if err := WriteFileIf(fn, strings.NewReader(`{"revision":1}`), 0644, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// a failed write must not replace the file
+	if err := WriteFile(fn, io.MultiReader(strings.NewReader("x"), failingReader{}), 0644); err == nil {
+		t.Errorf("want write error")
+	}
+	bts, gen, err := ReadFileGen(fn)
+	if err != nil || string(bts) != `{"revision":1}` || gen != 0 {
+		t.Errorf("previous file damaged: %v - %s - gen %v", err, bts, gen)
+	}
+}
+
+// TestS3RoundTrip runs against an S3 compatible bucket - i.e. a local MinIO, see README;
+// skipped, unless CLOUDIO_TEST_S3_URL is set
+func TestS3RoundTrip(t *testing.T) {
+
+	u := os.Getenv("CLOUDIO_TEST_S3_URL")
+	if u == "" {
+		t.Skip("CLOUDIO_TEST_S3_URL not set")
+	}
+	orig := bucketURL
+	defer func() { bucketURL = orig }()
+	bucketURL = u
+
+	dir := fmt.Sprintf("cloudio-test/%v", time.Now().UnixNano())
+	fn := dir + "/1234.json"
+	defer Delete(fn)
+
+	if err := WriteFile(fn, strings.NewReader(`{"revision":1}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	bts, gen, err := ReadFileGen(fn)
+	if err != nil || string(bts) != `{"revision":1}` {
+		t.Fatalf("reading back: %v - %s", err, bts)
+	}
+	if err := WriteFileIf(fn, strings.NewReader(`{"revision":2}`), 0644, gen); err != nil {
+		t.Errorf("conditional write: %v", err)
+	}
+	if bts, err := ReadFile(fn); err != nil || string(bts) != `{"revision":2}` {
+		t.Errorf("reading back: %v - %s", err, bts)
+	}
+
+	objs, err := ReadDir(dir)
+	if err != nil || len(*objs) != 1 || !strings.HasSuffix((*objs)[0].Key, "1234.json") {
+		t.Errorf("listing %v: %v - %v objects", dir, err, len(*objs))
+	}
+
+	if err := Delete(fn); err != nil {
+		t.Errorf("deleting: %v", err)
+	}
+	if _, err := ReadFile(fn); !IsNotExist(err) {
+		t.Errorf("want not exist after delete - got %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package cloudio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"cloud.google.com/go/storage"
+	"gocloud.dev/blob"
+	"gocloud.dev/gcerrors"
+)
+
+// Conditional writes prevent overwriting changes made by others
+// between reading and writing a file - i.e. two browser tabs of one participant;
+// only Google cloud storage supports conditions - by object generation;
+// for other backends, generation is always 0 and writes are unconditional;
+// callers need to serialize read and write - see qst.Save1Versioned().
+
+// ErrConflict signals a file changed since it was read
+var ErrConflict = errors.New("file was changed concurrently")
+
+// ReadFileGen is ReadFile - additionally returning the generation of the file;
+// generation is 0 for non-existing files and for backends without generations
+func ReadFileGen(fileName string) (bts []byte, gen int64, err error) {
+	bts, gen, err = readRawGen(fileName)
+	if err != nil {
+		return
+	}
+	if IsEncrypted(bts) {
+		bts, _, err = decrypt(bts)
+		if err != nil {
+			err = fmt.Errorf("decrypting %v: %w", fileName, err)
+		}
+	}
+	return
+}
+
+// WriteFileIf is WriteFile - succeeding only if the file still has generation gen;
+// generation 0 means the file must not exist;
+// returns ErrConflict otherwise
+func WriteFileIf(fileName string, r io.Reader, perm os.FileMode, gen int64) error {
+
+	opts := &blob.WriterOptions{
+		BeforeWrite: func(as func(interface{}) bool) error {
+			var obj **storage.ObjectHandle
+			if as(&obj) {
+				cond := storage.Conditions{GenerationMatch: gen}
+				if gen == 0 {
+					cond = storage.Conditions{DoesNotExist: true}
+				}
+				*obj = (*obj).If(cond)
+			}
+			return nil
+		},
+	}
+	err := writeFile(fileName, r, opts)
+	if err != nil && gcerrors.Code(err) == gcerrors.FailedPrecondition {
+		return fmt.Errorf("%v: %w", fileName, ErrConflict)
+	}
+	return err
+}
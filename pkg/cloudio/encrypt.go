@@ -0,0 +1,285 @@
+package cloudio
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"strings"
+
+	"gocloud.dev/blob"
+)
+
+// Envelope encryption of questionnaire response files:
+// each file is encrypted with a random data key - AES-256-GCM;
+// the data key is encrypted with a master key - and stored in the file header
+// along with the ID of the master key;
+// thus master keys can be rotated - see Reencrypt().
+//
+// Master keys are configured via environment - cloudio is zero config:
+//    CLOUDIO_KEYS=2022b:base64key,2022a:base64key
+// or
+//    CLOUDIO_KEY_FILE=/path/to/keys - one id:base64key per line
+//
+// The first key encrypts; all keys decrypt.
+// Keys are 32 bytes - i.e. openssl rand -base64 32.
+// Without keys, files are written in plain text.
+// Plain text files are always read - for files written before encryption.
+
+// encMagic starts each encrypted file
+var encMagic = []byte("GQENC1\n")
+
+// questionnaire files below encPrefix are encrypted;
+// exports below encExclude are not - they are streamed
+var encPrefix = "responses/"
+var encExclude = "responses/downloaded/"
+
+// encHeaderT is the second line of an encrypted file
+type encHeaderT struct {
+	KeyID   string `json:"kid"`
+	DataKey string `json:"dek"` // data key - encrypted with master key KeyID - base64
+}
+
+type masterKeyT struct {
+	ID  string
+	Key []byte
+}
+
+// masterKeys - first one is current; nil if encryption is not configured
+var masterKeys []masterKeyT
+
+func init() {
+	var err error
+	masterKeys, err = loadMasterKeys()
+	if err != nil {
+		log.Fatalf("cloudio: encryption keys: %v", err)
+	}
+	if len(masterKeys) > 0 {
+		log.Printf("cloudio: response files encrypted with key %v - %v keys for decryption", masterKeys[0].ID, len(masterKeys))
+	}
+}
+
+// loadMasterKeys reads keys from environment
+func loadMasterKeys() ([]masterKeyT, error) {
+	lines := []string{}
+	if s := os.Getenv("CLOUDIO_KEYS"); s != "" {
+		lines = append(lines, strings.Split(s, ",")...)
+	}
+	if fn := os.Getenv("CLOUDIO_KEY_FILE"); fn != "" {
+		bts, err := os.ReadFile(fn)
+		if err != nil {
+			return nil, err
+		}
+		scn := bufio.NewScanner(bytes.NewReader(bts))
+		for scn.Scan() {
+			lines = append(lines, scn.Text())
+		}
+	}
+	return parseMasterKeys(lines)
+}
+
+// parseMasterKeys parses id:base64key lines; empty lines and # comments are skipped
+func parseMasterKeys(lines []string) ([]masterKeyT, error) {
+	keys := []masterKeyT{}
+	seen := map[string]bool{}
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("key line must be id:base64key")
+		}
+		id := strings.TrimSpace(parts[0])
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("key %v: %w", id, err)
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("key %v: 32 bytes required - got %v", id, len(key))
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("key %v: duplicate id", id)
+		}
+		seen[id] = true
+		keys = append(keys, masterKeyT{ID: id, Key: key})
+	}
+	return keys, nil
+}
+
+// encrypted is true for files, which are encrypted on writing
+func encrypted(fileName string) bool {
+	if len(masterKeys) == 0 {
+		return false
+	}
+	fileName = strings.TrimPrefix(path.Clean(fileName), "/")
+	return strings.HasPrefix(fileName, encPrefix) &&
+		!strings.HasPrefix(fileName, encExclude) &&
+		strings.HasSuffix(fileName, ".json")
+}
+
+// IsEncrypted is true for contents written by encrypt()
+func IsEncrypted(bts []byte) bool {
+	return bytes.HasPrefix(bts, encMagic)
+}
+
+// seal encrypts plain with key; the nonce is prepended
+func seal(key, plain, additional []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plain, additional), nil
+}
+
+// open decrypts the output of seal()
+func open(key, sealed, additional []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	nonce := sealed[:gcm.NonceSize()]
+	return gcm.Open(nil, nonce, sealed[gcm.NonceSize():], additional)
+}
+
+// encrypt plain with a new data key - and the current master key
+func encrypt(plain []byte) ([]byte, error) {
+
+	mk := masterKeys[0]
+	dataKey := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, err
+	}
+	wrapped, err := seal(mk.Key, dataKey, []byte(mk.ID))
+	if err != nil {
+		return nil, err
+	}
+	hdr, err := json.Marshal(encHeaderT{KeyID: mk.ID, DataKey: base64.StdEncoding.EncodeToString(wrapped)})
+	if err != nil {
+		return nil, err
+	}
+	hdr = append(hdr, '\n')
+
+	body, err := seal(dataKey, plain, hdr) // header is authenticated
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]byte, 0, len(encMagic)+len(hdr)+len(body))
+	ret = append(ret, encMagic...)
+	ret = append(ret, hdr...)
+	return append(ret, body...), nil
+}
+
+// decrypt the output of encrypt(); returns the master key ID
+func decrypt(bts []byte) ([]byte, string, error) {
+
+	bts = bytes.TrimPrefix(bts, encMagic)
+	idx := bytes.IndexByte(bts, '\n')
+	if idx < 0 {
+		return nil, "", fmt.Errorf("encryption header missing")
+	}
+	hdrRaw := bts[:idx+1]
+	hdr := encHeaderT{}
+	if err := json.Unmarshal(hdrRaw, &hdr); err != nil {
+		return nil, "", fmt.Errorf("encryption header: %w", err)
+	}
+
+	var mk *masterKeyT
+	for i := range masterKeys {
+		if masterKeys[i].ID == hdr.KeyID {
+			mk = &masterKeys[i]
+		}
+	}
+	if mk == nil {
+		return nil, hdr.KeyID, fmt.Errorf("encrypted with key %v - which is not configured", hdr.KeyID)
+	}
+	wrapped, err := base64.StdEncoding.DecodeString(hdr.DataKey)
+	if err != nil {
+		return nil, hdr.KeyID, fmt.Errorf("data key: %w", err)
+	}
+	dataKey, err := open(mk.Key, wrapped, []byte(mk.ID))
+	if err != nil {
+		return nil, hdr.KeyID, fmt.Errorf("data key with key %v: %w", mk.ID, err)
+	}
+	plain, err := open(dataKey, bts[idx+1:], hdrRaw)
+	if err != nil {
+		return nil, hdr.KeyID, fmt.Errorf("contents: %w", err)
+	}
+	return plain, hdr.KeyID, nil
+}
+
+// Reencrypt encrypts all files below prefix with the current master key;
+// plain text files and files of older master keys are rewritten;
+// returns the number of rewritten files
+func Reencrypt(prefix string) (int, error) {
+
+	if len(masterKeys) == 0 {
+		return 0, fmt.Errorf("no encryption keys configured")
+	}
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	buck, err := bucket()
+	if err != nil {
+		return 0, err
+	}
+	objs := &[]*blob.ListObject{}
+	list(context.Background(), buck, prefix, 0, 10, objs)
+	if err := buck.Close(); err != nil {
+		log.Printf("Error closing bucket: %v", err)
+	}
+
+	cntr := 0
+	for _, obj := range *objs {
+		key := strings.ReplaceAll(obj.Key, "\\", "/")
+		if obj.IsDir || strings.HasSuffix(key, ".attrs") || !encrypted(key) {
+			continue
+		}
+		bts, err := readRaw(key)
+		if err != nil {
+			return cntr, err
+		}
+		if IsEncrypted(bts) {
+			var kid string
+			bts, kid, err = decrypt(bts)
+			if err != nil {
+				return cntr, fmt.Errorf("%v: %w", key, err)
+			}
+			if kid == masterKeys[0].ID {
+				continue
+			}
+		}
+		if err := WriteFile(key, bytes.NewReader(bts), 0644); err != nil {
+			return cntr, err
+		}
+		cntr++
+	}
+	log.Printf("cloudio: %v files below %v re-encrypted with key %v", cntr, prefix, masterKeys[0].ID)
+	return cntr, nil
+}
@@ -0,0 +1,61 @@
+package cloudio
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+)
+
+func TestEncrypt(t *testing.T) {
+
+	k1 := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+	k2 := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 32))
+
+	if _, err := parseMasterKeys([]string{"short:" + base64.StdEncoding.EncodeToString([]byte("abc"))}); err == nil {
+		t.Errorf("short key should be rejected")
+	}
+
+	orig := masterKeys
+	defer func() { masterKeys = orig }()
+
+	var err error
+	masterKeys, err = parseMasterKeys([]string{"# comment", "k1:" + k1, ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !encrypted("./responses/fmt/2022-05/1234.json") || encrypted("config.json") {
+		t.Errorf("only files below responses should be encrypted")
+	}
+	if encrypted("responses/downloaded/fmt-2022-05.csv") || encrypted("responses/downloaded/fmt-2022-05.json") {
+		t.Errorf("exports should not be encrypted")
+	}
+
+	plain := []byte(`{"user_id": "1234"}`)
+	enc, err := encrypt(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(enc) || bytes.Contains(enc, plain) {
+		t.Errorf("contents not encrypted")
+	}
+
+	// rotation: k2 is current, k1 still decrypts
+	masterKeys, _ = parseMasterKeys([]string{"k2:" + k2, "k1:" + k1})
+	dec, kid, err := decrypt(enc)
+	if err != nil || kid != "k1" || !bytes.Equal(dec, plain) {
+		t.Errorf("decrypt with rotated keys: %v - %v - %s", err, kid, dec)
+	}
+
+	// tampering
+	enc[len(enc)-1] ^= 1
+	if _, _, err := decrypt(enc); err == nil {
+		t.Errorf("tampered contents should not decrypt")
+	}
+
+	// key removed
+	enc, _ = encrypt(plain)
+	masterKeys, _ = parseMasterKeys([]string{"k1:" + k1})
+	if _, _, err := decrypt(enc); err == nil {
+		t.Errorf("key k2 is not configured - should not decrypt")
+	}
+}
@@ -38,6 +38,21 @@ func ServeFileBulk(w http.ResponseWriter, req *http.Request) {
 	// fpth := path.Join(".", "app-bucket", pth)
 	fpth := path.Join(".", pth)
 
+	// encrypted files are decrypted in memory - no hand off to ServeFileStream()
+	if encrypted(fpth) {
+		bts, err := ReadFile(fpth)
+		if err != nil {
+			logAndShow("cloudio.ServeFileBulk(): Error reading %v: %v", fpth, err)
+			return
+		}
+		w.Header().Set("Content-Length", fmt.Sprintf("%v", len(bts)))
+		if m := mime.TypeByExtension(filepath.Ext(pth)); m != "" {
+			w.Header().Set("Content-Type", m)
+		}
+		w.Write(bts)
+		return
+	}
+
 	// log.Printf("cloudio.Stream(): initiating download %v", fpth)
 
 	ctx := context.Background()
@@ -14,7 +14,12 @@ var regD = regexp.MustCompile(`Mobile.{0,}Firefox`)
 
 // IsMobile just answers yes or no
 func IsMobile(r *http.Request) bool {
-	ua := r.Header.Get("User-Agent")
+	return IsMobileUA(r.Header.Get("User-Agent"))
+}
+
+// IsMobileUA is IsMobile for a user agent string;
+// i.e. for user agents stored in questionnaires
+func IsMobileUA(ua string) bool {
 	if regC.MatchString(ua) {
 		// log.Printf("UA1 %s", ua)
 		return true
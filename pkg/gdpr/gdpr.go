@@ -0,0 +1,485 @@
+// Package gdpr handles data subject requests;
+// all files of a participant are found by user ID
+// across surveys and waves - including downloaded copies;
+// they can be exported as JSON and erased;
+// closed questionnaires are anonymized after a retention period.
+//
+// Registrations are not keyed by user ID, but by email.
+package gdpr
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/zew/go-questionnaire/pkg/cloudio"
+	"github.com/zew/go-questionnaire/pkg/qst"
+)
+
+// RegistrationDir contains the registration CSV files - on the local file system
+var RegistrationDir = filepath.Join(".", "static", "fmt-registrations")
+
+// maxDepth of directories below qst.BasePath();
+// responses/downloaded/<survey>/<wave>/empty/<user>.json
+const maxDepth = 5
+
+// walk calls fn for all files below dir
+func walk(dir string, depth int, fn func(key string) error) error {
+	objs, err := cloudio.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("reading dir %v: %w", dir, err)
+	}
+	for _, obj := range *objs {
+		key := strings.ReplaceAll(obj.Key, "\\", "/")
+		if obj.IsDir {
+			if depth < maxDepth {
+				if err := walk(key, depth+1, fn); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if err := fn(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inSurvey is true, if key is below a directory surveyID;
+// or surveyID is empty
+func inSurvey(key, surveyID string) bool {
+	return surveyID == "" || strings.Contains(key, "/"+surveyID+"/")
+}
+
+// Find returns the bucket keys of all questionnaire files of userID;
+// restricted to surveyID, unless empty - user IDs might be reused across surveys
+func Find(userID, surveyID string) ([]string, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, fmt.Errorf("user ID is empty")
+	}
+	keys := []string{}
+	err := walk(qst.BasePath(), 0, func(key string) error {
+		base := path.Base(key)
+		if (base == userID+".json" || base == userID+".json.attrs") && inSurvey(key, surveyID) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	return keys, err
+}
+
+// matchingLines returns the lines of a registration file
+// with a semicolon separated field equal to email;
+// and the remaining lines
+func matchingLines(bts []byte, email string) (matching, rest []string) {
+	scn := bufio.NewScanner(bytes.NewReader(bts))
+	for scn.Scan() {
+		line := scn.Text()
+		found := false
+		for _, fld := range strings.Split(line, ";") {
+			if strings.EqualFold(strings.Trim(fld, " \""), email) {
+				found = true
+				break
+			}
+		}
+		if found {
+			matching = append(matching, line)
+		} else {
+			rest = append(rest, line)
+		}
+	}
+	return
+}
+
+// registrationFiles returns the registration CSV files
+func registrationFiles() []string {
+	fns, err := filepath.Glob(filepath.Join(RegistrationDir, "*.csv"))
+	if err != nil {
+		log.Printf("gdpr: listing registrations: %v", err)
+	}
+	return fns
+}
+
+// ExportT contains all data of a participant
+type ExportT struct {
+	UserID        string                     `json:"user_id"`
+	SurveyID      string                     `json:"survey_id,omitempty"`
+	Email         string                     `json:"email,omitempty"`
+	Exported      time.Time                  `json:"exported"`
+	Files         map[string]json.RawMessage `json:"files"`                   // bucket key => questionnaire
+	Registrations map[string][]string        `json:"registrations,omitempty"` // file => lines
+}
+
+// Export collects all data of userID;
+// and the registrations of email - if not empty
+func Export(userID, surveyID, email string, now time.Time) (*ExportT, error) {
+
+	keys, err := Find(userID, surveyID)
+	if err != nil {
+		return nil, err
+	}
+	exp := &ExportT{
+		UserID:   userID,
+		SurveyID: surveyID,
+		Email:    email,
+		Exported: now,
+		Files:    map[string]json.RawMessage{},
+	}
+	for _, key := range keys {
+		bts, err := cloudio.ReadFile(key)
+		if err != nil {
+			return nil, fmt.Errorf("reading %v: %w", key, err)
+		}
+		if !json.Valid(bts) {
+			bts, _ = json.Marshal(string(bts))
+		}
+		exp.Files[key] = bts
+	}
+
+	if email != "" {
+		for _, fn := range registrationFiles() {
+			bts, err := os.ReadFile(fn)
+			if err != nil {
+				return nil, fmt.Errorf("reading %v: %w", fn, err)
+			}
+			if matching, _ := matchingLines(bts, email); len(matching) > 0 {
+				if exp.Registrations == nil {
+					exp.Registrations = map[string][]string{}
+				}
+				exp.Registrations[fn] = matching
+			}
+		}
+	}
+	return exp, nil
+}
+
+// ReportT lists the changes of Erase()
+type ReportT struct {
+	Deleted   []string `json:"deleted"`   // questionnaire files - and exports which cannot be edited - i.e. xlsx; must be regenerated
+	Rewritten []string `json:"rewritten"` // CSV and JSONL exports and registrations - rows removed
+}
+
+// downloadDir contains the exports
+func downloadDir() string {
+	return path.Join(qst.BasePath(), "downloaded")
+}
+
+// Erase deletes all files of userID - see Find();
+// rows of userID are removed from the CSV and JSONL exports in the download dir;
+// other exports are deleted;
+// rows of email are removed from the registrations - if not empty
+func Erase(userID, surveyID, email string) (*ReportT, error) {
+
+	keys, err := Find(userID, surveyID)
+	if err != nil {
+		return nil, err
+	}
+	rep := &ReportT{}
+	for _, key := range keys {
+		if err := cloudio.Delete(key); err != nil {
+			return rep, fmt.Errorf("deleting %v: %w", key, err)
+		}
+		rep.Deleted = append(rep.Deleted, key)
+	}
+
+	// exports - first column is the user ID
+	downloaded := downloadDir()
+	objs, err := cloudio.ReadDir(downloaded)
+	if err != nil {
+		return rep, fmt.Errorf("reading dir %v: %w", downloaded, err)
+	}
+	for _, obj := range *objs {
+		key := strings.ReplaceAll(obj.Key, "\\", "/")
+		if obj.IsDir || (surveyID != "" && !strings.HasPrefix(path.Base(key), surveyID+"-")) {
+			continue
+		}
+		var changed bool
+		switch path.Ext(key) {
+		case ".csv":
+			changed, err = eraseCSVRows(key, userID)
+		case ".jsonl":
+			changed, err = eraseJSONLRows(key, userID)
+		default:
+			if err := cloudio.Delete(key); err != nil {
+				return rep, fmt.Errorf("deleting %v: %w", key, err)
+			}
+			rep.Deleted = append(rep.Deleted, key)
+			continue
+		}
+		if err != nil {
+			return rep, err
+		}
+		if changed {
+			rep.Rewritten = append(rep.Rewritten, key)
+		}
+	}
+
+	if email != "" {
+		for _, fn := range registrationFiles() {
+			bts, err := os.ReadFile(fn)
+			if err != nil {
+				return rep, fmt.Errorf("reading %v: %w", fn, err)
+			}
+			matching, rest := matchingLines(bts, email)
+			if len(matching) == 0 {
+				continue
+			}
+			err = os.WriteFile(fn, []byte(strings.Join(rest, "\n")+"\n"), 0600)
+			if err != nil {
+				return rep, fmt.Errorf("writing %v: %w", fn, err)
+			}
+			rep.Rewritten = append(rep.Rewritten, fn)
+		}
+	}
+
+	log.Printf("gdpr: erased user %v - survey '%v' - %v files deleted - %v rewritten",
+		userID, surveyID, len(rep.Deleted), len(rep.Rewritten))
+	return rep, nil
+}
+
+// sniffDelimiter returns the most frequent candidate delimiter
+// in the header row - outside of quotes; exports have various dialects - see tf.CSVDialectT
+func sniffDelimiter(header string) rune {
+	cnts := map[rune]int{}
+	quoted := false
+	for _, r := range header {
+		if r == '"' {
+			quoted = !quoted
+		}
+		if !quoted && strings.ContainsRune(";,\t|", r) {
+			cnts[r]++
+		}
+	}
+	best := ';'
+	for _, r := range []rune{';', '\t', ',', '|'} {
+		if cnts[r] > cnts[best] {
+			best = r
+		}
+	}
+	return best
+}
+
+// csvFileT is an export in one of various dialects - see tf.CSVDialectT
+type csvFileT struct {
+	recs  [][]string
+	comma rune
+	bom   bool
+	crlf  bool
+}
+
+var bom = []byte("\xef\xbb\xbf")
+
+// readCSV parses an export;
+// quoted fields may span several lines
+func readCSV(key string) (*csvFileT, error) {
+	bts, err := cloudio.ReadFile(key)
+	if err != nil {
+		return nil, fmt.Errorf("reading %v: %w", key, err)
+	}
+	cf := &csvFileT{bom: bytes.HasPrefix(bts, bom)}
+	bts = bytes.TrimPrefix(bts, bom)
+
+	header := string(bts)
+	if idx := strings.IndexByte(header, '\n'); idx > -1 {
+		header = header[:idx]
+	}
+	cf.crlf = strings.HasSuffix(header, "\r")
+	rdr := csv.NewReader(bytes.NewReader(bts))
+	rdr.Comma = sniffDelimiter(header)
+	rdr.FieldsPerRecord = -1
+	rdr.LazyQuotes = true
+	cf.comma = rdr.Comma
+	cf.recs, err = rdr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("parsing %v: %w", key, err)
+	}
+	return cf, nil
+}
+
+// write the export back - in its dialect
+func (cf *csvFileT) write(key string) error {
+	buf := &bytes.Buffer{}
+	if cf.bom {
+		buf.Write(bom)
+	}
+	wtr := csv.NewWriter(buf)
+	wtr.Comma = cf.comma
+	wtr.UseCRLF = cf.crlf
+	if err := wtr.WriteAll(cf.recs); err != nil {
+		return fmt.Errorf("writing %v: %w", key, err)
+	}
+	if err := cloudio.WriteFile(key, buf, 0600); err != nil {
+		return fmt.Errorf("writing %v: %w", key, err)
+	}
+	return nil
+}
+
+// eraseCSVRows removes the rows with userID in the first column
+func eraseCSVRows(key, userID string) (bool, error) {
+	cf, err := readCSV(key)
+	if err != nil {
+		return false, err
+	}
+	rest := make([][]string, 0, len(cf.recs))
+	for _, rec := range cf.recs {
+		if len(rec) > 0 && rec[0] == userID {
+			continue
+		}
+		rest = append(rest, rec)
+	}
+	if len(rest) == len(cf.recs) {
+		return false, nil
+	}
+	cf.recs = rest
+	return true, cf.write(key)
+}
+
+// clearCSVColumns empties the values of columns with names in cols
+func clearCSVColumns(key string, cols ...string) (bool, error) {
+	cf, err := readCSV(key)
+	if err != nil {
+		return false, err
+	}
+	if len(cf.recs) == 0 {
+		return false, nil
+	}
+	idxs := []int{}
+	for idx, name := range cf.recs[0] {
+		for _, col := range cols {
+			if name == col {
+				idxs = append(idxs, idx)
+			}
+		}
+	}
+	changed := false
+	for _, rec := range cf.recs[1:] {
+		for _, idx := range idxs {
+			if idx < len(rec) && rec[idx] != "" {
+				rec[idx] = ""
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		return false, nil
+	}
+	return true, cf.write(key)
+}
+
+// eraseJSONLRows removes the lines with user_id equal to userID
+func eraseJSONLRows(key, userID string) (bool, error) {
+	bts, err := cloudio.ReadFile(key)
+	if err != nil {
+		return false, fmt.Errorf("reading %v: %w", key, err)
+	}
+	lines := bytes.SplitAfter(bts, []byte("\n"))
+	rest := make([][]byte, 0, len(lines))
+	for i, line := range lines {
+		if len(bytes.TrimSpace(line)) == 0 {
+			rest = append(rest, line)
+			continue
+		}
+		rec := struct {
+			UserID string `json:"user_id"`
+		}{}
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return false, fmt.Errorf("parsing %v line %v: %w", key, i+1, err)
+		}
+		if rec.UserID == userID {
+			continue
+		}
+		rest = append(rest, line)
+	}
+	if len(rest) == len(lines) {
+		return false, nil
+	}
+	err = cloudio.WriteFile(key, bytes.NewReader(bytes.Join(rest, nil)), 0600)
+	if err != nil {
+		return false, fmt.Errorf("writing %v: %w", key, err)
+	}
+	return true, nil
+}
+
+// Anonymize clears RemoteIP and UserAgent of questionnaires
+// closed more than retention ago;
+// columns remote_ip and user_agent of CSV exports are cleared for all rows;
+// XLSX exports are deleted - they must be regenerated;
+// returns the number of anonymized questionnaires
+func Anonymize(retention time.Duration, now time.Time) (int, error) {
+	if retention <= 0 {
+		return 0, fmt.Errorf("retention must be positive")
+	}
+	cntr := 0
+	err := walk(qst.BasePath(), 0, func(key string) error {
+		if !strings.HasSuffix(key, ".json") {
+			return nil
+		}
+		q, err := qst.Load1(key)
+		if err != nil {
+			log.Printf("gdpr: skipping %v: %v", key, err)
+			return nil
+		}
+		if q.ClosingTime.IsZero() || now.Sub(q.ClosingTime) < retention {
+			return nil
+		}
+		if q.RemoteIP == "" && q.UserAgent == "" {
+			return nil
+		}
+		q.RemoteIP = ""
+		q.UserAgent = ""
+		if err := q.Save1Versioned(key); err != nil {
+			if errors.Is(err, qst.ErrStale) {
+				log.Printf("gdpr: skipping %v: %v", key, err) // next run
+				return nil
+			}
+			return fmt.Errorf("saving %v: %w", key, err)
+		}
+		cntr++
+		return nil
+	})
+	if err != nil {
+		return cntr, err
+	}
+	log.Printf("gdpr: anonymized %v questionnaires closed before %v", cntr, now.Add(-retention).Format("2006-01-02"))
+	return cntr, anonymizeExports()
+}
+
+// anonymizeExports removes IP and user agent from the exports in the download dir
+func anonymizeExports() error {
+	objs, err := cloudio.ReadDir(downloadDir())
+	if err != nil {
+		return fmt.Errorf("reading dir %v: %w", downloadDir(), err)
+	}
+	for _, obj := range *objs {
+		key := strings.ReplaceAll(obj.Key, "\\", "/")
+		if obj.IsDir {
+			continue
+		}
+		switch path.Ext(key) {
+		case ".csv":
+			changed, err := clearCSVColumns(key, "remote_ip", "user_agent")
+			if err != nil {
+				return err
+			}
+			if changed {
+				log.Printf("gdpr: cleared IP and user agent in %v", key)
+			}
+		case ".xlsx":
+			if err := cloudio.Delete(key); err != nil {
+				return fmt.Errorf("deleting %v: %w", key, err)
+			}
+			log.Printf("gdpr: deleted %v - contains IP and user agent", key)
+		}
+	}
+	return nil
+}
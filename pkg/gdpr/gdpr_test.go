@@ -0,0 +1,158 @@
+package gdpr
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zew/go-questionnaire/pkg/cloudio"
+	"github.com/zew/go-questionnaire/pkg/qst"
+)
+
+// setup changes into a temp dir - the local bucket is ./app-bucket;
+// participant 1234 took part in surveys fmt and cep
+func setup(t *testing.T, closed time.Time) {
+
+	wd, _ := os.Getwd()
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	for _, fn := range []string{"fmt/2022-05/1234", "fmt/2022-05/5678", "cep/2022-05/1234"} {
+		q := &qst.QuestionnaireT{UserID: path.Base(fn), RemoteIP: "10.0.0.1", UserAgent: "Firefox", ClosingTime: closed}
+		if err := q.Save1(path.Join(qst.BasePath(), fn)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func writeFile(t *testing.T, key, content string) {
+	if err := cloudio.WriteFile(key, strings.NewReader(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, key string) string {
+	bts, err := cloudio.ReadFile(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(bts)
+}
+
+func jsonFiles(keys []string) []string {
+	ret := []string{}
+	for _, key := range keys {
+		if strings.HasSuffix(key, ".json") {
+			ret = append(ret, key)
+		}
+	}
+	return ret
+}
+
+func TestFind(t *testing.T) {
+	setup(t, time.Time{})
+	tests := []struct {
+		userID, surveyID string
+		want             int
+	}{
+		{"1234", "", 2},
+		{"1234", "fmt", 1},
+		{"5678", "cep", 0},
+		{"123", "", 0},
+	}
+	for _, tt := range tests {
+		keys, err := Find(tt.userID, tt.surveyID)
+		if err != nil || len(jsonFiles(keys)) != tt.want {
+			t.Errorf("Find(%v, %v): %v - %v - want %v files", tt.userID, tt.surveyID, err, keys, tt.want)
+		}
+	}
+	if _, err := Find(" ", ""); err == nil {
+		t.Errorf("empty user ID should be rejected")
+	}
+}
+
+func TestErase(t *testing.T) {
+
+	setup(t, time.Time{})
+
+	dl := path.Join(qst.BasePath(), "downloaded")
+	// tab separated - with byte order mark - with a multi-line free text answer
+	writeFile(t, path.Join(dl, "fmt-2022-05.csv"),
+		"\xef\xbb\xbfuser_id\tcomment\n1234\t\"first line\n1234\tsecond line\"\n5678\tok\n")
+	writeFile(t, path.Join(dl, "fmt-2022-05-long.csv"), "user_id|name|value\n5678|q1|2\n1234|q1|3\n")
+	writeFile(t, path.Join(dl, "fmt-2022-05.jsonl"), `{"user_id":"5678"}`+"\n"+`{"user_id":"1234"}`+"\n")
+	writeFile(t, path.Join(dl, "fmt-2022-05.xlsx"), "binary")
+	writeFile(t, path.Join(dl, "cep-2022-05.csv"), "user_id;x\n1234;1\n")
+
+	regDir := t.TempDir()
+	orig := RegistrationDir
+	RegistrationDir = regDir
+	defer func() { RegistrationDir = orig }()
+	reg := filepath.Join(regDir, "registrations.csv")
+	os.WriteFile(reg, []byte("Smith;a@b.c;2022\nMiller;x@y.z;2022\n"), 0600)
+
+	rep, err := Erase("1234", "fmt", "A@B.C")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jsonFiles(rep.Deleted)) != 1 || len(rep.Rewritten) != 4 {
+		t.Errorf("report %+v", rep)
+	}
+	if _, err := cloudio.ReadFile(path.Join(dl, "fmt-2022-05.xlsx")); !cloudio.IsNotExist(err) {
+		t.Errorf("xlsx export cannot be edited - should be deleted: %v", err)
+	}
+
+	want := map[string]string{
+		"fmt-2022-05.csv":      "\xef\xbb\xbfuser_id\tcomment\n5678\tok\n",
+		"fmt-2022-05-long.csv": "user_id|name|value\n5678|q1|2\n",
+		"fmt-2022-05.jsonl":    `{"user_id":"5678"}` + "\n",
+		"cep-2022-05.csv":      "user_id;x\n1234;1\n", // other survey
+	}
+	for fn, content := range want {
+		if got := readFile(t, path.Join(dl, fn)); got != content {
+			t.Errorf("%v:\n%q\nwant\n%q", fn, got, content)
+		}
+	}
+	if bts, _ := os.ReadFile(reg); string(bts) != "Miller;x@y.z;2022\n" {
+		t.Errorf("registrations: %q", bts)
+	}
+	if keys, _ := Find("1234", ""); len(jsonFiles(keys)) != 1 {
+		t.Errorf("cep file should remain: %v", keys)
+	}
+}
+
+func TestAnonymize(t *testing.T) {
+
+	now := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	setup(t, now.AddDate(0, 0, -400))
+
+	dl := path.Join(qst.BasePath(), "downloaded")
+	writeFile(t, path.Join(dl, "fmt-2022-05.csv"), "user_id;remote_ip;user_agent;x\n1234;10.0.0.1;Firefox;1\n")
+	writeFile(t, path.Join(dl, "fmt-2022-05.xlsx"), "binary")
+
+	// within retention
+	if cnt, _ := Anonymize(500*24*time.Hour, now); cnt != 0 {
+		t.Errorf("anonymized %v within retention", cnt)
+	}
+
+	cnt, err := Anonymize(365*24*time.Hour, now)
+	if err != nil || cnt != 3 {
+		t.Errorf("anonymized %v - %v", cnt, err)
+	}
+	q, err := qst.Load1(path.Join(qst.BasePath(), "fmt/2022-05/1234"))
+	if err != nil || q.RemoteIP != "" || q.UserAgent != "" {
+		t.Errorf("not anonymized: %v - %v %v", err, q.RemoteIP, q.UserAgent)
+	}
+	if got, want := readFile(t, path.Join(dl, "fmt-2022-05.csv")), "user_id;remote_ip;user_agent;x\n1234;;;1\n"; got != want {
+		t.Errorf("csv export not anonymized:\n%q\nwant\n%q", got, want)
+	}
+	if _, err := cloudio.ReadFile(path.Join(dl, "fmt-2022-05.xlsx")); !cloudio.IsNotExist(err) {
+		t.Errorf("xlsx export should be deleted: %v", err)
+	}
+
+}
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"net/http"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/zew/go-questionnaire/pkg/chart"
+	"github.com/zew/go-questionnaire/pkg/lgn"
+	"github.com/zew/go-questionnaire/pkg/qst"
+	"github.com/zew/go-questionnaire/pkg/sessx"
+	"github.com/zew/go-questionnaire/pkg/stats"
+	"github.com/zew/go-questionnaire/pkg/tf"
+	"github.com/zew/go-questionnaire/pkg/tpl"
+	"github.com/zew/go-questionnaire/pkg/trl"
+)
+
+// scanning all response files is expensive;
+// results are kept until refresh=1 is requested
+var dashboardCache = struct {
+	sync.Mutex
+	mp map[string]*stats.WaveT
+}{
+	mp: map[string]*stats.WaveT{},
+}
+
+// waveStats returns the statistics of responses/<survey>/<wave>;
+// from cache, unless refresh is set
+func waveStats(surveyID, waveID string, refresh bool) (*stats.WaveT, error) {
+
+	key := surveyID + "/" + waveID
+
+	dashboardCache.Lock()
+	defer dashboardCache.Unlock()
+
+	if ws, ok := dashboardCache.mp[key]; ok && !refresh {
+		return ws, nil
+	}
+
+	ws := stats.NewWave(surveyID, waveID)
+	pth := path.Join(qst.BasePath(), surveyID, waveID)
+	if err := tf.RetrieveEach(pth, "1", ws.Add); err != nil {
+		return nil, fmt.Errorf("could not scan %v: %w", pth, err)
+	}
+	ws.Finalize()
+	dashboardCache.mp[key] = ws
+	return ws, nil
+}
+
+// adminSurveyWave checks for admin role
+// and returns the URL params survey_id and wave_id;
+// responds with an error message, if ok is false
+func adminSurveyWave(w http.ResponseWriter, r *http.Request, sess *sessx.SessT) (surveyID, waveID string, ok bool) {
+
+	l, isLoggedIn, err := lgn.LoggedInCheck(w, r)
+	if err != nil {
+		tf.LogAndRespond(w, r, "LoggedInCheck failed.", err)
+		return
+	}
+	if !isLoggedIn {
+		tf.LogAndRespond(w, r, "You are are not logged in.", nil)
+		return
+	}
+	if !l.HasRole("admin") {
+		tf.LogAndRespond(w, r, "Login succeeded, but must have role 'admin'", nil)
+		return
+	}
+
+	surveyID, ok = sess.ReqParam("survey_id")
+	if !ok {
+		tf.LogAndRespond(w, r, "You need to specify a survey_id parameter.", nil)
+		return
+	}
+	waveID, ok = sess.ReqParam("wave_id")
+	if !ok {
+		tf.LogAndRespond(w, r, "You need to specify a wave_id parameter.", nil)
+		return
+	}
+	return surveyID, waveID, true
+}
+
+// DashboardH shows completion and dropout statistics of a wave;
+// you need to be logged in with admin role;
+// survey_id and wave_id must be set as URL params;
+// refresh=1 rescans the response files
+func DashboardH(w http.ResponseWriter, r *http.Request) {
+
+	sess := sessx.New(w, r)
+
+	surveyID, waveID, ok := adminSurveyWave(w, r, sess)
+	if !ok {
+		return
+	}
+	refresh, _ := sess.ReqParam("refresh")
+
+	ws, err := waveStats(surveyID, waveID, refresh != "")
+	if err != nil {
+		tf.LogAndRespond(w, r, "Could not compute statistics.", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	tpl.ExecContent(w, r, dashboardHTML(ws), "layout.html")
+
+}
+
+// dashboardHTML renders ws as a sequence of tables
+func dashboardHTML(ws *stats.WaveT) string {
+
+	b := &bytes.Buffer{}
+	esc := html.EscapeString
+
+	fmt.Fprintf(b, "<h3>Dashboard %v - %v</h3>\n", esc(ws.SurveyID), esc(ws.WaveID))
+	fmt.Fprintf(b,
+		"<p>Computed %v - <a href='?survey_id=%v&wave_id=%v&refresh=1'>refresh</a></p>\n",
+		ws.Computed.Format(time.RFC1123), esc(ws.SurveyID), esc(ws.WaveID),
+	)
+
+	fmt.Fprint(b, "<table>\n")
+	fmt.Fprintf(b, "<tr><td>Started</td><td>%v</td></tr>\n", ws.Started)
+	fmt.Fprintf(b, "<tr><td>Finished</td><td>%v</td><td>%4.1f%%</td></tr>\n", ws.Finished, stats.Share(ws.Finished, ws.Started))
+	fmt.Fprintf(b, "<tr><td>Median completion time</td><td>%v</td></tr>\n", ws.MedianCompletion.Round(time.Second))
+	fmt.Fprintf(b, "<tr><td>Mobile</td><td>%v</td><td>%4.1f%%</td></tr>\n", ws.Mobile, stats.Share(ws.Mobile, ws.Started))
+	fmt.Fprintf(b, "<tr><td>Desktop</td><td>%v</td><td>%4.1f%%</td></tr>\n", ws.Desktop, stats.Share(ws.Desktop, ws.Started))
+	for _, c := range stats.Sorted(ws.EndStates) {
+		fmt.Fprintf(b, "<tr><td>Ended %v</td><td>%v</td><td>%4.1f%%</td></tr>\n", esc(c.Name), c.Count, stats.Share(c.Count, ws.Started))
+	}
+	if ws.Unknown > 0 {
+		fmt.Fprintf(b, "<tr><td>Device unknown</td><td>%v</td><td>%4.1f%%</td></tr>\n", ws.Unknown, stats.Share(ws.Unknown, ws.Started))
+	}
+	fmt.Fprint(b, "</table>\n")
+
+	fmt.Fprint(b, "<h4>Over time</h4>\n")
+	if len(ws.Days) > 1 {
+		fmt.Fprint(b, dashboardChart(ws))
+	}
+	fmt.Fprint(b, "<table>\n")
+	fmt.Fprint(b, "<tr><th>Day</th><th>Started</th><th>Finished</th><th>Cum. started</th><th>Cum. finished</th></tr>\n")
+	for _, d := range ws.Days {
+		fmt.Fprintf(b, "<tr><td>%v</td><td>%v</td><td>%v</td><td>%v</td><td>%v</td></tr>\n",
+			d.Day, d.Started, d.Finished, d.CumStarted, d.CumFini)
+	}
+	fmt.Fprint(b, "</table>\n")
+
+	fmt.Fprint(b, "<h4>Dropout by last reached page</h4>\n<table>\n")
+	fmt.Fprint(b, "<tr><th>Page</th><th>Unfinished</th><th>Share</th></tr>\n")
+	pages := make([]int, 0, len(ws.DropoutByPage))
+	for pg := range ws.DropoutByPage {
+		pages = append(pages, pg)
+	}
+	sort.Ints(pages)
+	for _, pg := range pages {
+		cnt := ws.DropoutByPage[pg]
+		fmt.Fprintf(b, "<tr><td>%v</td><td>%v</td><td>%4.1f%%</td></tr>\n", pg+1, cnt, stats.Share(cnt, ws.Started))
+	}
+	fmt.Fprint(b, "</table>\n")
+
+	fmt.Fprint(b, "<h4>Languages</h4>\n<table>\n")
+	for _, c := range stats.Sorted(ws.Langs) {
+		fmt.Fprintf(b, "<tr><td>%v</td><td>%v</td><td>%4.1f%%</td></tr>\n", esc(c.Name), c.Count, stats.Share(c.Count, ws.Started))
+	}
+	fmt.Fprint(b, "</table>\n")
+
+	fmt.Fprint(b, "<h4>Validation errors by input</h4>\n<table>\n")
+	fmt.Fprint(b, "<tr><th>Input</th><th>Failed submits</th></tr>\n")
+	for _, c := range stats.Sorted(ws.ErrorsByInput) {
+		fmt.Fprintf(b, "<tr><td>%v</td><td>%v</td></tr>\n", esc(c.Name), c.Count)
+	}
+	fmt.Fprint(b, "</table>\n")
+
+	return b.String()
+}
+
+// dashboardChart shows cumulated started and finished questionnaires
+func dashboardChart(ws *stats.WaveT) string {
+	c := chart.ChartT{
+		Width:  640,
+		Series: []chart.SeriesT{{Name: trl.S{"en": "Started"}}, {Name: trl.S{"en": "Finished"}}},
+	}
+	for _, d := range ws.Days {
+		c.Categories = append(c.Categories, trl.S{"en": d.Day[5:]}) // mm-dd
+		c.Series[0].Values = append(c.Series[0].Values, float64(d.CumStarted))
+		c.Series[1].Values = append(c.Series[1].Values, float64(d.CumFini))
+	}
+	return chart.Line(c, "en")
+}
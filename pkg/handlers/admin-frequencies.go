@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/zew/go-questionnaire/pkg/qst"
+	"github.com/zew/go-questionnaire/pkg/sessx"
+	"github.com/zew/go-questionnaire/pkg/stats"
+	"github.com/zew/go-questionnaire/pkg/tf"
+	"github.com/zew/go-questionnaire/pkg/tpl"
+)
+
+// FrequenciesH shows frequency tables for radios and dropdowns
+// and summary statistics for number inputs;
+// you need to be logged in with admin role;
+// survey_id and wave_id must be set as URL params;
+// attrs=country:de,sector:bank restricts to participants with these profile attributes;
+// finished=1 or finished=0 restricts to finished or unfinished questionnaires;
+// min_cell raises the threshold for small cell suppression;
+// lang sets the language of the labels
+func FrequenciesH(w http.ResponseWriter, r *http.Request) {
+
+	sess := sessx.New(w, r)
+
+	surveyID, waveID, ok := adminSurveyWave(w, r, sess)
+	if !ok {
+		return
+	}
+
+	filter := stats.FilterT{}
+	attrs, _ := sess.ReqParam("attrs")
+	filter.Attrs = stats.ParseAttrFilter(attrs)
+	filter.Finished, _ = sess.ReqParam("finished")
+
+	minCellStr, _ := sess.ReqParam("min_cell")
+	minCell, _ := strconv.Atoi(minCellStr)
+
+	lang, _ := sess.ReqParam("lang")
+	if lang == "" {
+		lang = "en"
+	}
+
+	fr := stats.NewFrequencies(filter, minCell, lang)
+	pth := path.Join(qst.BasePath(), surveyID, waveID)
+	if err := tf.RetrieveEach(pth, "1", fr.Add); err != nil {
+		tf.LogAndRespond(w, r, "Could not compute frequencies.", err)
+		return
+	}
+	fr.Finalize()
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	tpl.ExecContent(w, r, frequenciesHTML(surveyID, waveID, fr), "layout.html")
+
+}
+
+// frequenciesHTML renders one table per question;
+// suppressed counts are shown as '<MinCell'
+func frequenciesHTML(surveyID, waveID string, fr *stats.FrequenciesT) string {
+
+	b := &bytes.Buffer{}
+	esc := html.EscapeString
+
+	fmt.Fprintf(b, "<h3>Frequencies %v - %v</h3>\n", esc(surveyID), esc(waveID))
+	fmt.Fprintf(b, "<p>%v questionnaire(s); counts below %v are suppressed", fr.N, fr.MinCell)
+	if len(fr.Filter.Attrs) > 0 {
+		fmt.Fprintf(b, "; attributes %v", esc(fmt.Sprint(fr.Filter.Attrs)))
+	}
+	if fr.Filter.Finished == "1" {
+		fmt.Fprint(b, "; finished only")
+	}
+	if fr.Filter.Finished == "0" {
+		fmt.Fprint(b, "; unfinished only")
+	}
+	fmt.Fprint(b, "</p>\n")
+
+	for _, qu := range fr.Questions {
+
+		fmt.Fprintf(b, "<h4>%v <small>%v</small></h4>\n", esc(qu.Name), esc(qu.Label))
+
+		if qu.Type == "number" {
+			nm := qu.Numeric
+			if nm.Suppressed {
+				fmt.Fprintf(b, "<p>N = %v - too few responses</p>\n", nm.N)
+				continue
+			}
+			fmt.Fprint(b, "<table>\n")
+			fmt.Fprint(b, "<tr><th>N</th><th>Mean</th><th>P10</th><th>P25</th><th>Median</th><th>P75</th><th>P90</th></tr>\n")
+			fmt.Fprintf(b,
+				"<tr><td>%v</td><td>%.2f</td><td>%.2f</td><td>%.2f</td><td>%.2f</td><td>%.2f</td><td>%.2f</td></tr>\n",
+				nm.N, nm.Mean, nm.Q10, nm.Q25, nm.Median, nm.Q75, nm.Q90,
+			)
+			fmt.Fprint(b, "</table>\n")
+			continue
+		}
+
+		fmt.Fprint(b, "<table>\n")
+		fmt.Fprint(b, "<tr><th>Value</th><th>Label</th><th>Count</th><th>Share</th></tr>\n")
+		for _, o := range qu.Options {
+			cnt, share := fmt.Sprint(o.Count), fmt.Sprintf("%4.1f%%", stats.Share(o.Count, qu.N))
+			if qu.NSuppressed {
+				share = ""
+			}
+			if o.Suppressed {
+				cnt, share = fmt.Sprintf("&lt;%v", fr.MinCell), ""
+			}
+			fmt.Fprintf(b, "<tr><td>%v</td><td>%v</td><td>%v</td><td>%v</td></tr>\n", esc(o.Value), esc(o.Label), cnt, share)
+		}
+		n := fmt.Sprint(qu.N)
+		if qu.NSuppressed {
+			n = "suppressed"
+		}
+		fmt.Fprintf(b, "<tr><td></td><td>N</td><td>%v</td><td></td></tr>\n", n)
+		fmt.Fprint(b, "</table>\n")
+	}
+
+	return b.String()
+}
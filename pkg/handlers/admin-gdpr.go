@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/zew/go-questionnaire/pkg/cfg"
+	"github.com/zew/go-questionnaire/pkg/gdpr"
+	"github.com/zew/go-questionnaire/pkg/lgn"
+	"github.com/zew/go-questionnaire/pkg/sessx"
+	"github.com/zew/go-questionnaire/pkg/tf"
+	"github.com/zew/go-questionnaire/pkg/tpl"
+)
+
+// gdprAdmin checks for admin role;
+// changes require POST with form token
+func gdprAdmin(w http.ResponseWriter, r *http.Request, sess *sessx.SessT, change bool) bool {
+
+	l, isLoggedIn, err := lgn.LoggedInCheck(w, r)
+	if err != nil {
+		tf.LogAndRespond(w, r, "LoggedInCheck failed.", err)
+		return false
+	}
+	if !isLoggedIn {
+		tf.LogAndRespond(w, r, "You are are not logged in.", nil)
+		return false
+	}
+	if !l.HasRole("admin") {
+		tf.LogAndRespond(w, r, "Login succeeded, but must have role 'admin'", nil)
+		return false
+	}
+	if !change {
+		return true
+	}
+	if r.Method != "POST" {
+		tf.LogAndRespond(w, r, "Changes require POST.", nil)
+		return false
+	}
+	token, _ := sess.ReqParam("token")
+	if err := lgn.ValidateFormToken(token); err != nil {
+		tf.LogAndRespond(w, r, "Invalid form token.", err)
+		return false
+	}
+	return true
+}
+
+// gdprConfirm renders a form to confirm a change via POST;
+// msg is HTML - escape request params
+func gdprConfirm(w http.ResponseWriter, r *http.Request, title, msg string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	body := fmt.Sprintf(`
+		<p>%v</p>
+		<input type="hidden" name="token" value="%v" />
+		<button type="submit" formaction="%v" >%v</button>
+		`,
+		msg, lgn.FormToken(), html.EscapeString(r.URL.String()), title,
+	)
+	mp := map[string]interface{}{
+		"HTMLTitle": title,
+		"Content":   body,
+	}
+	tpl.Exec(w, r, mp, "layout.html")
+}
+
+// gdprJSON writes v as JSON
+func gdprJSON(w http.ResponseWriter, v interface{}, attachment string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if attachment != "" {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%v", attachment))
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	enc.Encode(v)
+}
+
+// GDPRExportH returns all data of a participant as JSON;
+// user_id is required; survey_id restricts the search - user IDs might be reused across surveys;
+// email adds the registrations;
+// you need to be logged in with admin role
+func GDPRExportH(w http.ResponseWriter, r *http.Request) {
+
+	sess := sessx.New(w, r)
+	if !gdprAdmin(w, r, sess, false) {
+		return
+	}
+	userID, _ := sess.ReqParam("user_id")
+	surveyID, _ := sess.ReqParam("survey_id")
+	email, _ := sess.ReqParam("email")
+
+	exp, err := gdpr.Export(userID, surveyID, email, time.Now())
+	if err != nil {
+		tf.LogAndRespond(w, r, "Export failed.", err)
+		return
+	}
+	gdprJSON(w, exp, fmt.Sprintf("gdpr-export-%v.json", userID))
+}
+
+// GDPREraseH deletes all data of a participant;
+// params as GDPRExportH;
+// GET asks for confirmation; POST erases and returns a report
+func GDPREraseH(w http.ResponseWriter, r *http.Request) {
+
+	sess := sessx.New(w, r)
+	userID, _ := sess.ReqParam("user_id")
+	surveyID, _ := sess.ReqParam("survey_id")
+	email, _ := sess.ReqParam("email")
+
+	if r.Method != "POST" {
+		if !gdprAdmin(w, r, sess, false) {
+			return
+		}
+		keys, err := gdpr.Find(userID, surveyID)
+		if err != nil {
+			tf.LogAndRespond(w, r, "Search failed.", err)
+			return
+		}
+		gdprConfirm(w, r, "Erase",
+			fmt.Sprintf("Erase %v files of user '%v' in survey '%v' - and registrations of '%v'?", len(keys), html.EscapeString(userID), html.EscapeString(surveyID), html.EscapeString(email)))
+		return
+	}
+
+	if !gdprAdmin(w, r, sess, true) {
+		return
+	}
+	rep, err := gdpr.Erase(userID, surveyID, email)
+	if err != nil {
+		tf.LogAndRespond(w, r, "Erasure incomplete.", err)
+		return
+	}
+	gdprJSON(w, rep, "")
+}
+
+// GDPRAnonymizeH clears IP and user agent of questionnaires
+// closed more than retention_days ago;
+// default is config setting retention_days;
+// GET asks for confirmation; POST anonymizes
+func GDPRAnonymizeH(w http.ResponseWriter, r *http.Request) {
+
+	sess := sessx.New(w, r)
+
+	days := cfg.Get().RetentionDays
+	if s, ok := sess.ReqParam("retention_days"); ok {
+		var err error
+		days, err = strconv.Atoi(s)
+		if err != nil {
+			tf.LogAndRespond(w, r, "Invalid retention_days.", err)
+			return
+		}
+	}
+	if days < 1 {
+		tf.LogAndRespond(w, r, "Specify retention_days - or set it in the config.", nil)
+		return
+	}
+
+	if r.Method != "POST" {
+		if !gdprAdmin(w, r, sess, false) {
+			return
+		}
+		gdprConfirm(w, r, "Anonymize",
+			fmt.Sprintf("Remove IP and user agent from all questionnaires closed more than %v days ago?", days))
+		return
+	}
+
+	if !gdprAdmin(w, r, sess, true) {
+		return
+	}
+	cntr, err := gdpr.Anonymize(time.Duration(days)*24*time.Hour, time.Now())
+	if err != nil {
+		tf.LogAndRespond(w, r, "Anonymization incomplete.", err)
+		return
+	}
+	gdprJSON(w, map[string]int{"anonymized": cntr}, "")
+}
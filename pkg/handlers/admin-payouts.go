@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/zew/go-questionnaire/pkg/qst"
+	"github.com/zew/go-questionnaire/pkg/sessx"
+	"github.com/zew/go-questionnaire/pkg/tf"
+)
+
+// PayoutsH returns the payouts of a wave as CSV - for bookkeeping;
+// one row per participant with a computed payout;
+// you need to be logged in with admin role;
+// survey_id and wave_id must be set as URL params
+func PayoutsH(w http.ResponseWriter, r *http.Request) {
+
+	sess := sessx.New(w, r)
+
+	surveyID, waveID, ok := adminSurveyWave(w, r, sess)
+	if !ok {
+		return
+	}
+
+	rows := [][]string{}
+	total := 0.0
+	pth := path.Join(qst.BasePath(), surveyID, waveID)
+	err := tf.RetrieveEach(pth, "1", func(q *qst.QuestionnaireT) error {
+		if q.Payout == nil {
+			return nil
+		}
+		currency := ""
+		if q.Payoff != nil {
+			currency = q.Payoff.Currency
+		}
+		rows = append(rows, []string{
+			q.UserID,
+			q.ClosingTime.Format("2006-01-02 15:04:05"),
+			q.Payout.Decision,
+			fmt.Sprint(q.Payout.Seed),
+			fmt.Sprintf("%.2f", q.Payout.Amount),
+			currency,
+		})
+		total += q.Payout.Amount
+		return nil
+	})
+	if err != nil {
+		tf.LogAndRespond(w, r, "Could not collect payouts.", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%v-%v-payouts.csv", surveyID, waveID))
+
+	wtr := csv.NewWriter(w)
+	wtr.Comma = ';'
+	wtr.Write([]string{"user_id", "closing_time", "decision", "seed", "amount", "currency"})
+	wtr.WriteAll(rows)
+	wtr.Write([]string{"total", "", "", "", fmt.Sprintf("%.2f", total), ""})
+	wtr.Flush()
+
+}
@@ -222,6 +222,20 @@ func RegisterHandlers(mux *http.ServeMux) {
 			Handler: LoginByHashID,
 			Keys:    []string{"login-by-hash-id"},
 		},
+		{
+			Urls:    []string{"/report"},
+			Title:   "Report of own answers",
+			Handler: ReportH,
+			Keys:    []string{"report"},
+			Allow:   map[handler.Privilege]bool{handler.LoggedIn: true},
+		},
+		{
+			Urls:    []string{"/consent-withdraw"},
+			Title:   "Withdraw consent",
+			Handler: ConsentWithdrawH,
+			Keys:    []string{"consent-withdraw"},
+			Allow:   map[handler.Privilege]bool{handler.LoggedIn: true},
+		},
 		{
 			Urls:    []string{"/logout"},
 			Title:   "Logout",
@@ -310,6 +324,48 @@ func RegisterHandlers(mux *http.ServeMux) {
 			Keys:    []string{"transferrer-endpoint"},
 			Allow:   map[handler.Privilege]bool{handler.Admin: true},
 		},
+		{
+			Urls:    []string{"/dashboard"},
+			Handler: DashboardH,
+			Title:   "Dashboard",
+			Keys:    []string{"dashboard"},
+			Allow:   map[handler.Privilege]bool{handler.Admin: true},
+		},
+		{
+			Urls:    []string{"/frequencies"},
+			Handler: FrequenciesH,
+			Title:   "Frequencies",
+			Keys:    []string{"frequencies"},
+			Allow:   map[handler.Privilege]bool{handler.Admin: true},
+		},
+		{
+			Urls:    []string{"/payouts"},
+			Handler: PayoutsH,
+			Title:   "Payouts",
+			Keys:    []string{"payouts"},
+			Allow:   map[handler.Privilege]bool{handler.Admin: true},
+		},
+		{
+			Urls:    []string{"/gdpr-export"},
+			Title:   "GDPR - export participant data",
+			Handler: GDPRExportH,
+			Keys:    []string{"gdpr-export"},
+			Allow:   map[handler.Privilege]bool{handler.Admin: true},
+		},
+		{
+			Urls:    []string{"/gdpr-erase"},
+			Title:   "GDPR - erase participant data",
+			Handler: GDPREraseH,
+			Keys:    []string{"gdpr-erase"},
+			Allow:   map[handler.Privilege]bool{handler.Admin: true},
+		},
+		{
+			Urls:    []string{"/gdpr-anonymize"},
+			Title:   "GDPR - anonymize closed questionnaires",
+			Handler: GDPRAnonymizeH,
+			Keys:    []string{"gdpr-anonymize"},
+			Allow:   map[handler.Privilege]bool{handler.Admin: true},
+		},
 	}
 
 	infos.MakeKeys()
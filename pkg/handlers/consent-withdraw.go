@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/zew/go-questionnaire/pkg/cfg"
+	"github.com/zew/go-questionnaire/pkg/gdpr"
+	"github.com/zew/go-questionnaire/pkg/lgn"
+	"github.com/zew/go-questionnaire/pkg/sessx"
+	"github.com/zew/go-questionnaire/pkg/tpl"
+)
+
+// ConsentWithdrawH lets participants withdraw their consent;
+// GET asks for confirmation; POST erases the responses of all waves and logs out;
+// if erasing fails, the participant stays logged in and can retry
+func ConsentWithdrawH(w http.ResponseWriter, r *http.Request) {
+
+	sess := sessx.New(w, r)
+
+	l, isLoggedIn, err := lgn.LoggedInCheck(w, r)
+	if err != nil {
+		helper(w, r, err, "LoggedInCheck error.")
+		return
+	}
+	if !isLoggedIn {
+		helper(w, r, nil, cfg.Get().Mp["login_by_hash_failed"].All()+"You are not logged in.")
+		return
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		helper(w, r, err, "parse form error")
+		return
+	}
+
+	q, err := loadQuestionnaire(w, r, l)
+	if err != nil {
+		helper(w, r, err)
+		return
+	}
+	if q.Consent == nil {
+		helper(w, r, nil, "No consent required for this survey.")
+		return
+	}
+	lc := q.LangCode
+
+	body := ""
+	if r.Method == "POST" {
+		token, _ := sess.ReqParam("token")
+		if err := lgn.ValidateFormToken(token); err != nil {
+			helper(w, r, err)
+			return
+		}
+		version := ""
+		if q.ConsentGiven != nil {
+			version = q.ConsentGiven.Markdown + " - " + q.ConsentGiven.Hash
+		}
+		rep, err := gdpr.Erase(l.User, q.Survey.Type, "") // all waves and exports
+		if err != nil {
+			log.Printf("user %v withdrew consent %v - erasing responses failed: %v", l.User, version, err)
+			body = fmt.Sprintf("<p>%v</p>", cfg.Get().Mp["consent_withdraw_failed"].Tr(lc))
+		} else {
+			if err := lgn.LogoutH(w, r); err != nil {
+				log.Printf("user %v withdrew consent - logout error %v", l.User, err)
+			}
+			log.Printf("user %v withdrew consent %v - %v files deleted - %v exports rewritten",
+				l.User, version, len(rep.Deleted), len(rep.Rewritten))
+			body = fmt.Sprintf("<p>%v</p>", cfg.Get().Mp["consent_withdrawn"].Tr(lc))
+		}
+	} else {
+		body = fmt.Sprintf(`
+			<p>%v</p>
+			<input type="hidden" name="token" value="%v" />
+			<button type="submit" formaction="%v" >%v</button>
+			`,
+			cfg.Get().Mp["consent_withdraw_confirm"].Tr(lc),
+			lgn.FormToken(),
+			cfg.Pref("/consent-withdraw"),
+			cfg.Get().Mp["consent_withdraw"].Tr(lc),
+		)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	mp := map[string]interface{}{
+		"LangCode":  lc,
+		"Site":      q.Survey.Type,
+		"HTMLTitle": cfg.Get().Mp["consent_withdraw"].Tr(lc),
+		"Content":   body,
+	}
+	tpl.Exec(w, r, mp, "layout.html")
+}
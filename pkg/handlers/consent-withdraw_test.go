@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/zew/go-questionnaire/pkg/cfg"
+	"github.com/zew/go-questionnaire/pkg/cloudio"
+	"github.com/zew/go-questionnaire/pkg/lgn"
+	"github.com/zew/go-questionnaire/pkg/qst"
+	"github.com/zew/go-questionnaire/pkg/sessx"
+)
+
+// withdrawSession logs in participant userID with questionnaire q;
+// returns the session cookie
+func withdrawSession(t *testing.T, userID string, q *qst.QuestionnaireT) *http.Cookie {
+	h := sessx.Mgr().LoadAndSave(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sess := sessx.New(w, r)
+		sess.PutObject("login", lgn.LoginT{User: userID, Attrs: map[string]string{"survey_id": "cons", "wave_id": "2022-05"}})
+		sess.PutObject("questionnaire", q)
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	cookies := rec.Result().Cookies()
+	if len(cookies) == 0 {
+		t.Fatal("no session cookie")
+	}
+	return cookies[0]
+}
+
+// withdraw posts the confirmation; returns the response body
+func withdraw(cookie *http.Cookie) string {
+	form := url.Values{"token": {lgn.FormToken()}}
+	req := httptest.NewRequest("POST", "/consent-withdraw", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(cookie)
+	rec := httptest.NewRecorder()
+	sessx.Mgr().LoadAndSave(http.HandlerFunc(ConsentWithdrawH)).ServeHTTP(rec, req)
+	return rec.Body.String()
+}
+
+func TestConsentWithdrawH(t *testing.T) {
+
+	chdirTemp(t)
+	cfg.LoadFakeConfigForTests()
+	lgn.Load(strings.NewReader(`{"salt": "test-salt"}`))
+
+	write := func(key, content string) {
+		if err := cloudio.WriteFile(key, strings.NewReader(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("templates/layout.html", "{{.Content}}")
+	for _, fn := range []string{"nav-css-2020.html", "example-01.html", "example-02.html"} {
+		write(path.Join("templates", fn), "")
+	}
+
+	for _, fn := range []string{"cons/2022-04/1234", "cons/2022-05/1234", "cons/2022-05/5678"} {
+		q := &qst.QuestionnaireT{UserID: path.Base(fn)}
+		if err := q.Save1(path.Join(qst.BasePath(), fn)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	exists := func(fn string) bool {
+		_, err := cloudio.ReadFile(path.Join(qst.BasePath(), fn) + ".json")
+		return err == nil
+	}
+
+	q := &qst.QuestionnaireT{UserID: "1234", LangCode: "en", Consent: &qst.ConsentT{Markdown: "consent.md"}}
+	q.Survey = qst.SurveyT{Type: "cons", Year: 2022, Month: 5}
+	cookie := withdrawSession(t, "1234", q)
+
+	// export cannot be parsed - erasing fails
+	corrupt := path.Join(qst.BasePath(), "downloaded", "cons-2022-05.jsonl")
+	write(corrupt, "not json\n")
+	body := withdraw(cookie)
+	if !strings.Contains(body, cfg.Get().Mp["consent_withdraw_failed"].Tr("en")) {
+		t.Errorf("failure should be shown - got %q", body)
+	}
+
+	// retry - still logged in
+	if err := cloudio.Delete(corrupt); err != nil {
+		t.Fatal(err)
+	}
+	body = withdraw(cookie)
+	if !strings.Contains(body, cfg.Get().Mp["consent_withdrawn"].Tr("en")) {
+		t.Errorf("withdrawal should be confirmed - got %q", body)
+	}
+	if exists("cons/2022-04/1234") || exists("cons/2022-05/1234") || !exists("cons/2022-05/5678") {
+		t.Errorf("all waves of participant 1234 should be deleted - and only those")
+	}
+}
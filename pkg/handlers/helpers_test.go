@@ -0,0 +1,16 @@
+package handlers
+
+import (
+	"os"
+	"testing"
+)
+
+// chdirTemp changes into a temp dir for the test;
+// the local bucket is ./app-bucket
+func chdirTemp(t *testing.T) {
+	wd, _ := os.Getwd()
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
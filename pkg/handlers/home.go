@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"html"
 	"log"
@@ -80,6 +81,20 @@ func loadQuestionnaire(w http.ResponseWriter, r *http.Request, l *lgn.LoginT) (*
 		return q, err
 	}
 
+	// once per participant and wave
+	if !q.PrefillDone && q.HasPrefills() {
+		pthPrev := l.QuestPathWave(q.Survey.PreviousWaveID())
+		qPrev, err := qst.Load1(pthPrev)
+		if err != nil {
+			if !cloudio.IsNotExist(err) {
+				log.Printf("Loading previous wave %v failed: %v", pthPrev, err)
+			}
+			qPrev = nil
+		}
+		q.PrefillFromPrevious(qPrev)
+		log.Printf("Prefilled %v responses from previous wave %v", len(q.PreviousWave), pthPrev)
+	}
+
 	// since 2021-10 the base file contains the wave id;
 	// thus following two checks are much less important
 	if q.Survey.Type != l.Attrs["survey_id"] {
@@ -120,6 +135,31 @@ func helper(w http.ResponseWriter, r *http.Request, err error, msgs ...string) {
 	errorH(w, r, err.Error()+" - "+dbg.CallingLine())
 }
 
+// panelRedirect sends participants of a panel provider
+// back to the provider URL for their end state;
+// returns false, if nothing is configured
+func panelRedirect(w http.ResponseWriter, r *http.Request, q *qst.QuestionnaireT) bool {
+	pp, ok := cfg.Get().PanelProviders[q.Survey.Type]
+	if !ok || q.Attrs[pp.IDParam] == "" {
+		return false
+	}
+	endState := q.EndState
+	if endState == "" {
+		endState = qst.EndComplete // closed before end states existed
+	}
+	url, err := pp.RedirectURL(endState, q.Attrs)
+	if err != nil {
+		log.Print(err)
+		return false
+	}
+	if url == "" {
+		return false
+	}
+	log.Printf("user %v - %v - redirected to panel provider %v", q.UserID, endState, url)
+	http.Redirect(w, r, url, http.StatusSeeOther)
+	return true
+}
+
 // LoginByHashID is an entry point for HashIDs;
 //  it prepares the request params
 //   so that they can be processed below by lgn.LoginByHash
@@ -149,6 +189,88 @@ func LoginByHashID(w http.ResponseWriter, r *http.Request) {
 
 }
 
+// errClosedMeanwhile - the questionnaire was finished in another tab or session
+var errClosedMeanwhile = errors.New("questionnaire was closed meanwhile")
+
+// applyEndSteps records consent, screens out by quotas and end rules -
+// and computes the payoff for completed questionnaires
+func applyEndSteps(q *qst.QuestionnaireT, prevPage int, now time.Time) {
+	if err := q.ApplyConsent(prevPage, now); err != nil {
+		log.Print(err)
+	}
+	q.CheckQuotas(now)
+	q.ApplyEndRules(prevPage, now)
+	if err := q.ComputePayoff(now); err != nil {
+		log.Print(err)
+	}
+}
+
+// mergeStale is called, if the questionnaire was saved meanwhile -
+// by another browser tab, another session or the updater;
+// the saved questionnaire is loaded, the responses of this request are applied,
+// and the participant is kept on the page to review it;
+// thus neither the saved nor the submitted responses are lost;
+// paradata, check results and the end state of the submitted questionnaire sub are kept;
+// a questionnaire closed meanwhile is not changed - errClosedMeanwhile
+func mergeStale(w http.ResponseWriter, r *http.Request, l *lgn.LoginT, sub *qst.QuestionnaireT, prevPage int, savedFields map[string]string, now time.Time) (*qst.QuestionnaireT, error) {
+
+	sess := sessx.New(w, r)
+	sess.Remove(r.Context(), "questionnaire")
+	q, err := loadQuestionnaire(w, r, l)
+	if err != nil {
+		return q, err
+	}
+	if !q.ClosingTime.IsZero() {
+		return q, errClosedMeanwhile
+	}
+	if prevPage > len(q.Pages)-1 || len(q.Pages) != len(sub.Pages) {
+		return q, fmt.Errorf("page %v does not exist in saved questionnaire", prevPage)
+	}
+
+	for i1 := 0; i1 < len(q.Pages[prevPage].Groups); i1++ {
+		for i2, inp := range q.Pages[prevPage].Groups[i1].Inputs {
+			if val, ok := savedFields[inp.Name]; ok && !inp.IsLayout() {
+				q.Pages[prevPage].Groups[i1].Inputs[i2].Response = html.EscapeString(val)
+			}
+		}
+	}
+
+	// paradata and time limit results of this request
+	q.Pages[prevPage].Paradata = sub.Pages[prevPage].Paradata
+	if q.Pages[prevPage].Finished.IsZero() {
+		q.Pages[prevPage].Finished = sub.Pages[prevPage].Finished
+	}
+	for _, chk := range sub.Checks {
+		if chk.Page == prevPage && sub.CheckResults[chk.Name] != nil {
+			if q.CheckResults == nil {
+				q.CheckResults = map[string]*qst.CheckResultT{}
+			}
+			q.CheckResults[chk.Name] = sub.CheckResults[chk.Name]
+		}
+	}
+
+	q.CurrPage = prevPage
+	q.SaveConflict = true
+	if sub.EndState != "" {
+		q.End(sub.EndState, now)
+	}
+	applyEndSteps(q, prevPage, now)
+
+	q.EnumeratePages()
+	err = q.ComputeDynamicContent(q.CurrPage)
+	if err != nil {
+		log.Printf("ComputeDynamicContent computation for page %v caused error %v", q.CurrPage, err)
+	}
+
+	q2, _ := q.Split()
+	err = q2.Save1Versioned(l.QuestPath())
+	if err != nil {
+		return q, err
+	}
+	q.Revision = q2.Revision
+	return q, nil
+}
+
 // MainH loads and displays the questionnaire with page and lang_code
 func MainH(w http.ResponseWriter, r *http.Request) {
 
@@ -200,6 +322,7 @@ func MainH(w http.ResponseWriter, r *http.Request) {
 		helper(w, r, err)
 		return
 	}
+	q.SaveConflict = false
 
 	// Already finished?
 	closed := !q.ClosingTime.IsZero()
@@ -208,6 +331,15 @@ func MainH(w http.ResponseWriter, r *http.Request) {
 			//
 		} else {
 			s := cfg.Get().Mp["finished_by_participant"].All(q.ClosingTime.Format("02.01.2006 15:04"))
+			if q.EndState != "" && q.EndState != qst.EndComplete {
+				s = cfg.Get().Mp["end_state_"+q.EndState].All()
+			}
+			if lnk, _ := qst.ReportLink(q, nil, ""); lnk != "" {
+				s += "\n" + lnk
+			}
+			if panelRedirect(w, r, q) {
+				return
+			}
 			helper(w, r, nil, s)
 			return
 		}
@@ -291,13 +423,16 @@ func MainH(w http.ResponseWriter, r *http.Request) {
 	}
 
 	prevPage := q.PrevPage() // remember before
+	now := time.Now().Truncate(time.Second)
+	q.ParadataLeave(prevPage, now)
 
 	//
 	// Put request values into questionnaire
 	if q.Pages[prevPage].Finished.IsZero() {
-		q.Pages[prevPage].Finished = time.Now().Truncate(time.Second)
+		q.Pages[prevPage].Finished = now
 	}
-	savedFields := map[string]string{} // prevent repetitions for multiple radios with same name
+	locked := q.TimeLimitExceeded(prevPage, now) // late responses are discarded
+	savedFields := map[string]string{}           // prevent repetitions for multiple radios with same name
 	for i1 := 0; i1 < len(q.Pages[prevPage].Groups); i1++ {
 		for i2 := range q.Pages[prevPage].Groups[i1].Inputs {
 			inp := q.Pages[prevPage].Groups[i1].Inputs[i2]
@@ -307,7 +442,7 @@ func MainH(w http.ResponseWriter, r *http.Request) {
 			// log.Printf("checking for %v", inp.Name)
 			// amazingly, this works for scattered radio inputs as well
 			ok := sess.EffectiveIsSet(inp.Name)
-			if ok {
+			if ok && !locked {
 				val := sess.EffectiveStr(inp.Name)
 				savedFields[inp.Name] = val
 				val = html.EscapeString(val) // XSS prevention
@@ -325,10 +460,15 @@ func MainH(w http.ResponseWriter, r *http.Request) {
 	if sess.EffectiveStr("skip_validation") == "" && r.Method == "POST" {
 		var forward *qst.ErrorForward
 		err, forward = q.ValidateResponseData(prevPage, q.LangCode)
+		submit := sess.EffectiveStr("submitBtn")
+		err = q.ApplyTimeLimits(prevPage, now, submit != "prev", err)
+		err = q.ApplyChecks(prevPage, now, submit != "prev", err) // attempts only count for accepted submits
 		if err != nil {
-			submit := sess.EffectiveStr("submitBtn")
-			if submit != "prev" { // effectively allow going back - but not going forth
+			if q.EndState != "" { // ended by a validator or a check - stay on the end page
+				q.HasErrors = false
+			} else if submit != "prev" { // effectively allow going back - but not going forth
 				q.CurrPage = prevPage // Prevent changing page, keep participant on page with errors
+				q.ParadataFailedSubmit(prevPage)
 			} else {
 				q.HasErrors = false
 			}
@@ -352,6 +492,14 @@ func MainH(w http.ResponseWriter, r *http.Request) {
 			}
 			return
 		}
+	} else {
+		// no validation - skip_validation or page navigation by GET;
+		// time limits apply nevertheless
+		forward := q.CurrPage > prevPage
+		if err := q.ApplyTimeLimits(prevPage, now, forward, nil); err != nil {
+			q.CurrPage = prevPage
+			q.ParadataFailedSubmit(prevPage)
+		}
 	}
 
 	if r.RemoteAddr != "" {
@@ -361,10 +509,15 @@ func MainH(w http.ResponseWriter, r *http.Request) {
 
 	if ok := sess.EffectiveIsSet("finished"); ok {
 		if sess.EffectiveStr("finished") == qst.Finished {
-			q.ClosingTime = time.Now().Truncate(time.Second)
+			q.End(qst.EndComplete, now)
 		}
 	}
 
+	applyEndSteps(q, prevPage, now)
+
+	q.ParadataNavigation(prevPage, q.CurrPage)
+	q.ParadataEnter(q.CurrPage, prevPage, now, detect.IsMobile(r))
+
 	q.EnumeratePages()
 
 	err = q.ComputeDynamicContent(q.CurrPage)
@@ -372,18 +525,34 @@ func MainH(w http.ResponseWriter, r *http.Request) {
 		log.Printf("ComputeDynamicContent computation for page %v caused error %v", q.CurrPage, err)
 	}
 
+	q2, _ := q.Split()
+	err = q2.Save1Versioned(l.QuestPath())
+	if errors.Is(err, qst.ErrStale) {
+		log.Printf("user %v: %v - merging page %v", l.User, err, prevPage)
+		q, err = mergeStale(w, r, l, q, prevPage, savedFields, now)
+		if errors.Is(err, errClosedMeanwhile) {
+			log.Printf("user %v: %v - page %v discarded", l.User, err, prevPage)
+			helper(w, r, nil, cfg.Get().Mp["finished_by_participant"].All(q.ClosingTime.Format("02.01.2006 15:04")))
+			return
+		}
+		if err != nil {
+			helper(w, r, err, "Merging responses with the saved questionnaire caused error")
+			return
+		}
+	} else if err != nil {
+		helper(w, r, err, "Saving splitted responses to file caused error")
+		return
+	} else {
+		q.Revision = q2.Revision
+	}
+
 	//
 	//
 	// Save questionnaire into session
 	sess.PutObject("questionnaire", q)
 
-	//
-	//
-
-	q2, _ := q.Split()
-	err = q2.Save1(l.QuestPath())
-	if err != nil {
-		helper(w, r, err, "Saving splitted responses to file caused error")
+	// just closed - back to the panel provider
+	if !closed && !q.ClosingTime.IsZero() && panelRedirect(w, r, q) {
 		return
 	}
 
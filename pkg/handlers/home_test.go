@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/zew/go-questionnaire/pkg/cfg"
+	"github.com/zew/go-questionnaire/pkg/lgn"
+	"github.com/zew/go-questionnaire/pkg/qst"
+	"github.com/zew/go-questionnaire/pkg/sessx"
+)
+
+func TestMergeStale(t *testing.T) {
+
+	chdirTemp(t)
+	cfg.LoadFakeConfigForTests()
+
+	base := &qst.QuestionnaireT{LangCode: "en"}
+	base.Survey = qst.SurveyT{Type: "ms", Year: 2022, Month: 5}
+	for _, nm := range []string{"a", "b"} {
+		gr := base.AddPage().AddGroup()
+		gr.Cols = 1
+		inp := gr.AddInput()
+		inp.Name, inp.Type, inp.MaxChars = nm, "text", 10
+		inp.ColSpan, inp.ColSpanControl = 1, 1
+	}
+	if err := base.Save1(path.Join(qst.BasePath(), "ms-2022-05")); err != nil {
+		t.Fatal(err)
+	}
+	l := &lgn.LoginT{User: "1234", Attrs: map[string]string{"survey_id": "ms", "wave_id": "2022-05"}}
+	load := func() *qst.QuestionnaireT {
+		q, err := qst.Load1(path.Join(qst.BasePath(), "ms-2022-05"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		q.UserID = l.User
+		return q
+	}
+	now := time.Date(2022, 5, 3, 10, 0, 0, 0, time.UTC)
+
+	// another tab saved page 2 meanwhile
+	other := load()
+	other.ByName("b").Response = "other"
+	q2, _ := other.Split()
+	if err := q2.Save1Versioned(l.QuestPath()); err != nil {
+		t.Fatal(err)
+	}
+
+	merge := func(sub *qst.QuestionnaireT) (q *qst.QuestionnaireT, err error) {
+		h := sessx.Mgr().LoadAndSave(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			q, err = mergeStale(w, r, l, sub, 0, map[string]string{"a": "mine"}, now)
+		}))
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", nil))
+		return q, err
+	}
+
+	// stale submit finishing the questionnaire
+	sub := load()
+	sub.Pages[0].Paradata = &qst.ParadataT{TimeOnPage: 7}
+	sub.End(qst.EndComplete, now)
+	q, err := merge(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.ByName("a").Response != "mine" || q.ByName("b").Response != "other" {
+		t.Errorf("responses of both tabs should be kept")
+	}
+	if q.EndState != qst.EndComplete || q.ClosingTime.IsZero() {
+		t.Errorf("end state of the submit should be kept - got %q", q.EndState)
+	}
+	if q.Pages[0].Paradata == nil || q.Pages[0].Paradata.TimeOnPage != 7 {
+		t.Errorf("paradata of the submit should be kept")
+	}
+
+	// closed meanwhile
+	if _, err := merge(load()); !errors.Is(err, errClosedMeanwhile) {
+		t.Errorf("closed questionnaire should not be changed - got %v", err)
+	}
+}
@@ -2,17 +2,23 @@ package handlers
 
 import (
 	"bytes"
+	"encoding/csv"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
+	"reflect"
+	"regexp"
 	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/zew/go-questionnaire/pkg/cfg"
+	"github.com/zew/go-questionnaire/pkg/gdpr"
 	"github.com/zew/go-questionnaire/pkg/lgn"
+	"github.com/zew/go-questionnaire/pkg/xlsx"
 )
 
 var mtxFMT = sync.Mutex{}
@@ -42,7 +48,7 @@ type validatorHeadliner interface {
 
 func mustDir(fn string) (string, int64) {
 	// dir := cfg.Pref(filepath.Join("static", "registrations"))
-	dir := filepath.Join(".", "static", "fmt-registrations")
+	dir := gdpr.RegistrationDir
 	err := os.MkdirAll(dir, 0755)
 	if err != nil {
 		log.Panicf("Could not create dir %v; %v", dir, err)
@@ -70,7 +76,8 @@ func mustDir(fn string) (string, int64) {
 	return dir, fSize
 }
 
-// RegistrationsFMTDownload returns the CSV files
+// RegistrationsFMTDownload returns the CSV files;
+// format=xlsx returns an Excel file instead
 func RegistrationsFMTDownload(w http.ResponseWriter, r *http.Request) {
 
 	if cfg.Get().IsProduction {
@@ -92,6 +99,7 @@ func RegistrationsFMTDownload(w http.ResponseWriter, r *http.Request) {
 	lang := r.URL.Query().Get("lang")
 	if lang != "de" && lang != "en" {
 		fmt.Fprintf(w, "Append either ?lang='de' or ?lang='en' to select the language \n")
+		fmt.Fprintf(w, "Append &format=xlsx for an Excel file \n")
 		return
 	}
 
@@ -106,6 +114,19 @@ func RegistrationsFMTDownload(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if strings.ToLower(r.URL.Query().Get("format")) == "xlsx" {
+		var frm interface{} = formRegistrationFMTDe{}
+		if lang == "en" {
+			frm = formRegistrationFMTEn{}
+		}
+		bts, err = registrationsXLSX(bts, registrationLabels(frm))
+		if err != nil {
+			fmt.Fprintf(w, "Could not convert %v to XLSX; %v\n", fp, err)
+			return
+		}
+		atfn = fmt.Sprintf("attachment; filename=%v", strings.TrimSuffix(fn, ".csv")+".xlsx")
+	}
+
 	w.Header().Set("Content-type", "application/octet-stream")
 	w.Header().Set("Content-Disposition", atfn)
 	w.Header().Set("Pragma", "no-cache")
@@ -115,3 +136,60 @@ func RegistrationsFMTDownload(w http.ResponseWriter, r *http.Request) {
 	io.Copy(w, bRdr)
 
 }
+
+var labelFromFormTag = regexp.MustCompile(`label='([^']*)'`)
+
+// registrationLabels maps the struct field names
+// to the labels of the struc2frm form tags;
+// struct field names are the column names of s2f.HeaderRow()
+func registrationLabels(frm interface{}) map[string]string {
+	ret := map[string]string{}
+	tp := reflect.TypeOf(frm)
+	for i := 0; i < tp.NumField(); i++ {
+		fld := tp.Field(i)
+		ret[fld.Name] = fld.Name
+		if m := labelFromFormTag.FindStringSubmatch(fld.Tag.Get("form")); len(m) > 1 && m[1] != "" {
+			ret[fld.Name] = m[1]
+		}
+	}
+	return ret
+}
+
+// registrationsXLSX converts the semicolon separated registration CSV
+// into an Excel file with a data sheet and a labels sheet
+func registrationsXLSX(bts []byte, labels map[string]string) ([]byte, error) {
+
+	rdr := csv.NewReader(bytes.NewReader(bts))
+	rdr.Comma = ';'
+	rdr.LazyQuotes = true
+	rdr.FieldsPerRecord = -1 // header row and data rows may differ
+	records, err := rdr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	wb := xlsx.New()
+	data := wb.AddSheet("registrations")
+	data.FrozenRows = 1
+	lbls := wb.AddSheet("labels")
+	lbls.FrozenRows = 1
+	lbls.ColWidths = map[int]float64{0: 24, 1: 40}
+	lbls.AddStrings("column", "label")
+	lbls.BoldRow(0)
+
+	for idx, rec := range records {
+		// CSVLine() and HeaderRow() append a trailing separator
+		if len(rec) > 0 && rec[len(rec)-1] == "" {
+			rec = rec[:len(rec)-1]
+		}
+		data.AddStrings(rec...)
+		if idx == 0 {
+			data.BoldRow(0)
+			for _, col := range rec {
+				lbls.AddStrings(col, labels[col])
+			}
+		}
+	}
+
+	return wb.Bytes()
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/zew/go-questionnaire/pkg/cfg"
+	"github.com/zew/go-questionnaire/pkg/lgn"
+)
+
+// ReportH shows a printable copy of the participant's answers;
+// only after the questionnaire was finished;
+// reachable later via permalink login
+func ReportH(w http.ResponseWriter, r *http.Request) {
+
+	l, isLoggedIn, err := lgn.LoggedInCheck(w, r)
+	if err != nil {
+		helper(w, r, err, "LoggedInCheck error.")
+		return
+	}
+	if !isLoggedIn {
+		helper(w, r, nil, cfg.Get().Mp["login_by_hash_failed"].All()+"You are not logged in.")
+		return
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		helper(w, r, err, "parse form error")
+		return
+	}
+
+	q, err := loadQuestionnaire(w, r, l)
+	if err != nil {
+		helper(w, r, err)
+		return
+	}
+
+	if q.ClosingTime.IsZero() {
+		log.Printf("report for %v requested before closing", l.User)
+		helper(w, r, nil, cfg.Get().Mp["report_after_closing"].Tr(q.LangCode))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	q.Report(w)
+
+}
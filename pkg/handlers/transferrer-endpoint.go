@@ -1,10 +1,12 @@
 package handlers
 
 import (
+	"compress/gzip"
 	"fmt"
 	"log"
 	"net/http"
 	"path"
+	"strings"
 
 	"github.com/zew/go-questionnaire/pkg/cloudio"
 	"github.com/zew/go-questionnaire/pkg/qst"
@@ -20,6 +22,11 @@ import (
 // survey_id and wave_id must be set as URL params;
 // only finished questionnaires are included (q.ClosingTime != zero);
 // fetch_all=1 includes unfinished questionnaires;
+// format=CSV or format=XLSX return a spreadsheet instead of JSON;
+// format=LONG returns one CSV row per participant and input;
+// format=JSONL returns one line of JSON per participant;
+// format=CONJOINT returns the design matrix of conjoint experiments - one CSV row per participant, task and profile;
+// format=PANEL_WIDE or format=PANEL_LONG link participants across the comma separated waves in wave_id;
 func TransferrerEndpointH(w http.ResponseWriter, r *http.Request) {
 
 	deadLine, ok := r.Context().Deadline()
@@ -63,6 +70,33 @@ func TransferrerEndpointH(w http.ResponseWriter, r *http.Request) {
 
 	pth := path.Join(qst.BasePath(), surveyID, waveID)
 
+	format, _ := sess.ReqParam("format")
+	format = strings.ToUpper(format)
+
+	//
+	// streaming modes - questionnaires are not collected in memory
+	if format == "LONG" || format == "JSONL" || format == "CONJOINT" {
+		streamQs(w, pth, fetchAll, format, fmt.Sprintf("%v-%v", surveyID, waveID))
+		return
+	}
+
+	//
+	// panel mode - wave_id contains several comma separated waves
+	if format == "PANEL_WIDE" || format == "PANEL_LONG" {
+		cfgRem, err := directDownloadConfig(sess, surveyID, waveID)
+		if err != nil {
+			tf.LogAndRespond(w, r, "error reading transferrer config: %v", err)
+			return
+		}
+		csvPath, err := tf.ProcessPanel(cfgRem, strings.Split(waveID, ","), fetchAll, format == "PANEL_WIDE")
+		if err != nil {
+			tf.LogAndRespond(w, r, "error processing panel: %v", err)
+			return
+		}
+		serveAndDelete(w, r, csvPath, "text/csv; charset=utf-8")
+		return
+	}
+
 	//
 	//
 	qs, err := tf.RetrieveFromLocal(pth, fetchAll)
@@ -73,8 +107,7 @@ func TransferrerEndpointH(w http.ResponseWriter, r *http.Request) {
 
 	//
 	// GZIP mode - start
-	format, _ := sess.ReqParam("format")
-	if format != "CSV" {
+	if format != "CSV" && format != "XLSX" {
 		w.Header().Set("Content-Type", "application/json; charset=utf-8")
 		w.Header().Set("Content-Encoding", "gzip")
 		// w.Header().Set("Content-Length", fmt.Sprintf("%v", len(byts)))  // do not set, if response is gzipped !
@@ -86,45 +119,153 @@ func TransferrerEndpointH(w http.ResponseWriter, r *http.Request) {
 	//
 
 	//
-	// CSV direct download mode - start
+	// CSV/XLSX direct download mode - start
 	//  direct download requires only a *minimal* config for the requested survey_id stored on the server
 	// 	whereas client mode requires lots of configs for standalone operation.
+	cfgRem, err := directDownloadConfig(sess, surveyID, waveID)
+	if err != nil {
+		tf.LogAndRespond(w, r, "error reading transferrer config: %v", err)
+		return
+	}
+
+	saveQSFilesToDownloadDir := false
+	var csvPath string
+	if format == "XLSX" {
+		csvPath, err = tf.ProcessQsXLSX(cfgRem, qs, saveQSFilesToDownloadDir)
+	} else {
+		csvPath, err = tf.ProcessQs(cfgRem, qs, saveQSFilesToDownloadDir)
+	}
+	if err != nil {
+		tf.LogAndRespond(w, r, "error processing questionnaires from remote: %v", err)
+		return
+	}
+	log.Printf("%v file saved under: %v", format, csvPath)
+
+	contentType := "text/csv; charset=utf-8"
+	if format == "XLSX" {
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	serveAndDelete(w, r, csvPath, contentType)
+	// CSV direct download mode - start
+	//
+
+}
+
+// directDownloadConfig reads the minimal transferrer config
+// for surveyID stored on the server;
+// survey and wave and CSV dialect are taken from the request
+func directDownloadConfig(sess *sessx.SessT, surveyID, waveID string) (*tf.RemoteConnConfigT, error) {
+
 	remoteCfgPath := path.Join("transferrer", fmt.Sprintf("%v-remote.json", surveyID))
 	// instead of cfgRem := tf.LoadRemote()
 	cfgRem := &tf.RemoteConnConfigT{}
-	err = cloudio.ReadFileUnmarshal(remoteCfgPath, cfgRem)
+	err := cloudio.ReadFileUnmarshal(remoteCfgPath, cfgRem)
 	if err != nil {
-		s := fmt.Sprintf("error reading transferrer config %v: %%v", remoteCfgPath)
-		tf.LogAndRespond(w, r, s, err)
-		return
+		return nil, fmt.Errorf("%v: %w", remoteCfgPath, err)
 	}
 	// filling in survey name and wave ID from URL request
 	cfgRem.SurveyType = surveyID
 	cfgRem.WaveID = waveID
 
-	saveQSFilesToDownloadDir := false
-	csvPath, err := tf.ProcessQs(cfgRem, qs, saveQSFilesToDownloadDir)
-	if err != nil {
-		tf.LogAndRespond(w, r, "error processing questionnaires from remote: %v", err)
-		return
+	// CSV dialect from URL request
+	if delim, ok := sess.ReqParam("delimiter"); ok {
+		cfgRem.CSV.Delimiter = tf.ParseDelimiter(delim)
+	}
+	if dec, ok := sess.ReqParam("decimal"); ok {
+		cfgRem.CSV.DecimalSeparator = tf.ParseDelimiter(dec)
+	}
+	if _, ok := sess.ReqParam("bom"); ok {
+		cfgRem.CSV.BOM = true
+	}
+	if _, ok := sess.ReqParam("label_row"); ok {
+		cfgRem.CSV.LabelRow = true
+	}
+	if _, ok := sess.ReqParam("radio_labels"); ok {
+		cfgRem.CSV.RadioLabels = true
+	}
+	if lc, ok := sess.ReqParam("label_lang"); ok {
+		cfgRem.CSV.LabelLang = lc
 	}
-	log.Printf("CSV file saved under: %v", csvPath)
 
-	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
-	w.Header().Set("Content-Disposition", "attachment; filename="+path.Base(csvPath))
+	return cfgRem, nil
+}
 
-	bts, err := cloudio.ReadFile(csvPath)
+// serveAndDelete writes the file at pth into the response
+// and removes it from the bucket
+func serveAndDelete(w http.ResponseWriter, r *http.Request, pth, contentType string) {
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", "attachment; filename="+path.Base(pth))
+
+	bts, err := cloudio.ReadFile(pth)
 	if err != nil {
 		tf.LogAndRespond(w, r, "error opening CSV: %v", err)
 		return
 	}
-	err = cloudio.Delete(csvPath) // first delete the CSV, then serve the bytes
+	err = cloudio.Delete(pth) // first delete the CSV, then serve the bytes
 	if err != nil {
 		tf.LogAndRespond(w, r, "error deleting CSV: %v", err)
 	}
 
 	w.Write(bts)
-	// CSV direct download mode - start
-	//
+
+}
+
+// streamQs writes questionnaires with answers one by one - gzipped - into the response;
+// headers are sent before the first questionnaire is read,
+// thus errors can only be appended to the response body
+func streamQs(w http.ResponseWriter, pth, fetchAll, format, fnCore string) {
+
+	gz := gzip.NewWriter(w)
+	defer gz.Close()
+
+	w.Header().Set("Content-Encoding", "gzip")
+	cntr := 0
+
+	// questionnaires without answers are skipped - as in tf.ProcessQsLong()
+	each := func(write func(q *qst.QuestionnaireT) error) error {
+		return tf.RetrieveEach(pth, fetchAll, func(q *qst.QuestionnaireT) error {
+			if realEntries, _, _ := q.Statistics(); realEntries == 0 {
+				return nil
+			}
+			cntr++
+			return write(q)
+		})
+	}
+
+	var err error
+	if format == "LONG" {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", "attachment; filename="+fnCore+"-long.csv")
+		var lw *tf.LongWriterT
+		lw, err = tf.NewLongWriter(gz)
+		if err == nil {
+			err = each(lw.Write)
+		}
+		if err == nil {
+			err = lw.Flush()
+		}
+	} else if format == "CONJOINT" {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", "attachment; filename="+fnCore+"-conjoint.csv")
+		cw := tf.NewConjointWriter(gz)
+		err = each(cw.Write)
+		if err == nil {
+			err = cw.Flush()
+		}
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson; charset=utf-8")
+		w.Header().Set("Content-Disposition", "attachment; filename="+fnCore+".jsonl")
+		err = each(func(q *qst.QuestionnaireT) error {
+			return tf.WriteJSONL(gz, q)
+		})
+	}
+
+	if err != nil {
+		log.Printf("streaming %v failed after %v questionnaires: %v", format, cntr, err)
+		fmt.Fprintf(gz, "\nstreaming failed: %v\n", err)
+		return
+	}
+	log.Printf("%v questionnaires streamed as %v", cntr, format)
 
 }
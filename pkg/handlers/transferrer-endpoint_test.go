@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/zew/go-questionnaire/pkg/qst"
+)
+
+func Test_streamQs(t *testing.T) {
+
+	chdirTemp(t)
+
+	dir := path.Join(qst.BasePath(), "strm", "2022-05")
+	for userID, resp := range map[string]string{"1001": "answered", "1002": ""} {
+		q := &qst.QuestionnaireT{UserID: userID}
+		inp := q.AddPage().AddGroup().AddInput()
+		inp.Name, inp.Type, inp.Response = "comment", "text", resp
+		if err := q.Save1(path.Join(dir, userID)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	streamQs(rec, dir, "1", "JSONL", "strm-2022-05")
+	gz, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bts, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(bts)
+	if !strings.Contains(got, `"1001"`) || strings.Contains(got, `"1002"`) {
+		t.Errorf("questionnaires without answers should be skipped:\n%v", got)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"log"
 	"net/http"
+	neturl "net/url"
 	"strconv"
 	"strings"
 	"sync"
@@ -141,6 +142,19 @@ func LoginWithoutID(w http.ResponseWriter, r *http.Request) {
 	// forward to LoginByHashID
 	url := cfg.Pref(fmt.Sprintf("/d/%v--%v", cfg.Get().AnonymousSurveyID, hashID))
 
+	// pass on the IDs of the panel provider
+	if pp, ok := cfg.Get().PanelProviders[cfg.Get().AnonymousSurveyID]; ok {
+		vals := neturl.Values{}
+		for _, key := range pp.Inbound {
+			if val := r.URL.Query().Get(key); val != "" {
+				vals.Set(key, val)
+			}
+		}
+		if len(vals) > 0 {
+			url += "?" + vals.Encode()
+		}
+	}
+
 	if true {
 		// http.Redirect(w, r, url, http.StatusFound)
 		http.Redirect(w, r, url, http.StatusTemporaryRedirect)
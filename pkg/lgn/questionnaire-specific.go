@@ -137,6 +137,7 @@ func LoginByHash(w http.ResponseWriter, r *http.Request) (bool, error) {
 						for pk, pv := range dlr.Profile {
 							l.Attrs[pk] = pv
 						}
+						panelProviderAttrs(r, dlr.SurveyID, l.Attrs)
 
 						if sess.EffectiveStr("override_closure") == "true" {
 							sess.PutString("override_closure", "true")
@@ -161,11 +162,15 @@ func LoginByHash(w http.ResponseWriter, r *http.Request) (bool, error) {
 	l.Roles = map[string]string{}
 	l.Attrs = map[string]string{}
 
+	pp := cfg.Get().PanelProviders[r.Form.Get("sid")]
 	chkKeys := []string{}
 	for key := range r.Form {
 		if _, ok := exempted[key]; ok {
 			continue
 		}
+		if pp.IsInbound(key) {
+			continue
+		}
 		chkKeys = append(chkKeys, key)
 	}
 
@@ -208,6 +213,8 @@ func LoginByHash(w http.ResponseWriter, r *http.Request) (bool, error) {
 		}
 	}
 
+	panelProviderAttrs(r, r.Form.Get("sid"), l.Attrs)
+
 	if sess.EffectiveStr("override_closure") == "true" {
 		sess.PutString("override_closure", "true")
 	}
@@ -219,6 +226,28 @@ func LoginByHash(w http.ResponseWriter, r *http.Request) (bool, error) {
 	return true, nil
 }
 
+// panelProviderAttrs copies the inbound params
+// of the survey's panel provider into attrs;
+// values are stored raw - they go back into the redirect URL;
+// values with other than URL unreserved characters are discarded
+func panelProviderAttrs(r *http.Request, surveyID string, attrs map[string]string) {
+	pp, ok := cfg.Get().PanelProviders[surveyID]
+	if !ok {
+		return
+	}
+	for _, key := range pp.Inbound {
+		val := r.Form.Get(key)
+		if val == "" {
+			continue
+		}
+		if !cfg.ValidInbound(val) {
+			log.Printf("panel provider param %v - invalid value %q discarded", key, val)
+			continue
+		}
+		attrs[key] = val
+	}
+}
+
 // ReloadH removes the existing questioniare from the session,
 // reading it anew from the questionnaire template JSON file,
 // allowing to start anew
@@ -382,17 +411,20 @@ func basePath() string {
 // Similar to qst.QuestionnaireT.FilePath1()
 // See also userAttrs{}
 func (l *LoginT) QuestPath() string {
+	return l.QuestPathWave(l.Attrs["wave_id"])
+}
+
+// QuestPathWave is like QuestPath - but for any wave of the user's survey;
+// i.e. for the previous wave
+func (l *LoginT) QuestPathWave(waveID string) string {
 
 	userSurveyType := ""
-	userWaveID := ""
 	for attr, val := range l.Attrs {
 		if attr == "survey_id" {
 			userSurveyType = val
 		}
-		if attr == "wave_id" {
-			userWaveID = val
-		}
 	}
+	userWaveID := waveID
 
 	if userSurveyType == "" || userWaveID == "" {
 		log.Printf("Error constructing path for user questionnaire file; userSurveyType or userWaveID is empty: %v - %v", userSurveyType, userWaveID)
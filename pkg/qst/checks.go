@@ -0,0 +1,176 @@
+package qst
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/zew/go-questionnaire/pkg/cfg"
+	"github.com/zew/go-questionnaire/pkg/trl"
+)
+
+// results of CheckResultT
+const (
+	CheckPassed = "passed"
+	CheckFailed = "failed"
+)
+
+// CheckFlag as CheckT.Action records the failure
+// and lets the participant continue
+const CheckFlag = "flag"
+
+// CheckT is a declarative attention or comprehension check;
+// evaluated on submitting page Page forward - once the page is otherwise valid;
+// wrong answers are shown Explanation, while retries are left;
+// after the final failure, Action is taken
+type CheckT struct {
+	Name        string            `json:"name"`
+	Page        int               `json:"page"`
+	Expected    map[string]string `json:"expected"`              // input name => expected response
+	Retries     int               `json:"retries,omitempty"`     // attempts after the first failed one
+	Explanation trl.S             `json:"explanation,omitempty"` // shown on failure; default is core translation check_failed
+	Action      string            `json:"action,omitempty"`      // after final failure: CheckFlag - or an end state such as EndAttentionFailed
+}
+
+// CheckResultT records the attempts of a participant;
+// Result is empty, while the check is not passed and retries are left
+type CheckResultT struct {
+	Attempts int    `json:"attempts"`
+	Result   string `json:"result,omitempty"`
+}
+
+// correct is true, if all expected responses are given
+func (c CheckT) correct(q *QuestionnaireT) bool {
+	for name, exp := range c.Expected {
+		inp := q.ByName(name)
+		if inp == nil || strings.TrimSpace(inp.Response) != exp {
+			return false
+		}
+	}
+	return true
+}
+
+// checkErrMsg puts msg into the first input of the check on the page
+func (q *QuestionnaireT) checkErrMsg(c CheckT, msg string) {
+	for _, gr := range q.Pages[c.Page].Groups {
+		for _, inp := range gr.Inputs {
+			if _, ok := c.Expected[inp.Name]; ok {
+				inp.ErrMsg = msg
+				return
+			}
+		}
+	}
+}
+
+// ApplyChecks is called after ApplyTimeLimits for page pageNum with its error submitErr;
+// checks are evaluated on accepted forward submits only -
+// going back, rejected or timed out submits do not count as attempts;
+// returns an error, if the participant has to answer again;
+// final results are not evaluated again
+func (q *QuestionnaireT) ApplyChecks(pageNum int, now time.Time, forward bool, submitErr error) error {
+
+	if !forward || submitErr != nil {
+		return submitErr
+	}
+	if pageNum < 0 || pageNum > len(q.Pages)-1 {
+		return nil
+	}
+	if pd := q.Pages[pageNum].Paradata; pd != nil && pd.TimedOut {
+		return nil
+	}
+
+	var last error
+	lc := q.LangCode
+
+	for _, c := range q.Checks {
+
+		if c.Page != pageNum {
+			continue
+		}
+		if q.CheckResults == nil {
+			q.CheckResults = map[string]*CheckResultT{}
+		}
+		res, ok := q.CheckResults[c.Name]
+		if !ok {
+			res = &CheckResultT{}
+			q.CheckResults[c.Name] = res
+		}
+		if res.Result != "" {
+			continue
+		}
+
+		incomplete := false
+		for name := range c.Expected {
+			if inp := q.ByName(name); inp != nil && strings.TrimSpace(inp.Response) == "" {
+				incomplete = true
+			}
+		}
+		if incomplete {
+			last = fmt.Errorf(cfg.Get().Mp["check_incomplete"].Tr(lc))
+			q.checkErrMsg(c, last.Error())
+			continue
+		}
+
+		res.Attempts++
+		if c.correct(q) {
+			res.Result = CheckPassed
+			continue
+		}
+
+		if res.Attempts <= c.Retries {
+			expl := c.Explanation.TrSilent(lc)
+			if expl == "" {
+				expl = cfg.Get().Mp["check_failed"].Tr(lc)
+			}
+			last = fmt.Errorf("%v %v", expl, fmt.Sprintf(cfg.Get().Mp["check_attempt"].Tr(lc), res.Attempts+1, c.Retries+1))
+			q.checkErrMsg(c, last.Error())
+			continue
+		}
+
+		res.Result = CheckFailed
+		log.Printf("user %v failed check %v after %v attempts - %v", q.UserID, c.Name, res.Attempts, c.Action)
+		if _, ok := EndStatus[c.Action]; ok {
+			q.End(c.Action, now)
+		}
+	}
+
+	if last != nil {
+		q.HasErrors = true
+	}
+	return last
+}
+
+// validateChecks is part of Validate()
+func (q *QuestionnaireT) validateChecks() error {
+	names := map[string]bool{}
+	for _, c := range q.Checks {
+		if c.Name == "" || names[c.Name] {
+			return fmt.Errorf("check name '%v' empty or not unique", c.Name)
+		}
+		names[c.Name] = true
+		if c.Page < 0 || c.Page > len(q.Pages)-1 {
+			return fmt.Errorf("check %v - page %v out of range", c.Name, c.Page)
+		}
+		if len(c.Expected) == 0 {
+			return fmt.Errorf("check %v - no expected responses", c.Name)
+		}
+		if _, ok := EndStatus[c.Action]; !ok && c.Action != "" && c.Action != CheckFlag {
+			return fmt.Errorf("check %v - action %q neither %q nor an end state", c.Name, c.Action, CheckFlag)
+		}
+		for name := range c.Expected {
+			onPage := false
+			for _, gr := range q.Pages[c.Page].Groups {
+				for _, inp := range gr.Inputs {
+					if inp.Name == name && !inp.IsLayout() {
+						onPage = true
+					}
+				}
+			}
+			if !onPage {
+				return fmt.Errorf("check %v - input %v not on page %v", c.Name, name, c.Page)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package qst
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zew/go-questionnaire/pkg/cfg"
+)
+
+func TestQuestionnaireT_ApplyChecks(t *testing.T) {
+
+	cfg.LoadFakeConfigForTests()
+
+	q := newTestQ(2, "compr_a", "compr_b")
+	q.Pages[1].EndState = EndAttentionFailed
+	q.Checks = []CheckT{{
+		Name:     "compr",
+		Expected: map[string]string{"compr_a": "3", "compr_b": "7"},
+		Retries:  1,
+		Action:   EndAttentionFailed,
+	}}
+	if err := q.validateChecks(); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2022, 5, 3, 10, 0, 0, 0, time.UTC)
+
+	set := func(a, b string) {
+		q.ByName("compr_a").Response = a
+		q.ByName("compr_b").Response = b
+	}
+
+	set("3", "")
+	if err := q.ApplyChecks(0, now, true, nil); err == nil || q.CheckResults["compr"].Attempts != 0 {
+		t.Errorf("incomplete answers should not count as attempt")
+	}
+
+	set("3", "8")
+
+	// going back does not count as attempt
+	if err := q.ApplyChecks(0, now, false, nil); err != nil || q.CheckResults["compr"].Attempts != 0 {
+		t.Errorf("going back should not count as attempt: %v", err)
+	}
+
+	// neither does a submit rejected before MinSeconds
+	q.Pages[0].MinSeconds = 10
+	q.ParadataEnter(0, 0, now, false)
+	err := q.ApplyTimeLimits(0, now.Add(5*time.Second), true, nil)
+	if err = q.ApplyChecks(0, now, true, err); err == nil || q.CheckResults["compr"].Attempts != 0 {
+		t.Errorf("rejected submit should not count as attempt: %v", err)
+	}
+	q.Pages[0].MinSeconds = 0
+
+	if err := q.ApplyChecks(0, now, true, nil); err == nil || q.ByName("compr_a").ErrMsg == "" {
+		t.Errorf("first failure should demand a retry")
+	}
+
+	set("3", "9")
+	if err := q.ApplyChecks(0, now, true, nil); err != nil {
+		t.Errorf("final failure should not be a validation error: %v", err)
+	}
+	res := q.CheckResults["compr"]
+	if res.Attempts != 2 || res.Result != CheckFailed {
+		t.Errorf("got %+v", res)
+	}
+	if q.EndState != EndAttentionFailed || q.CurrPage != 1 {
+		t.Errorf("action should end the questionnaire: %q page %v", q.EndState, q.CurrPage)
+	}
+
+	// final results are kept
+	set("3", "7")
+	q.ApplyChecks(0, now, true, nil)
+	if res.Result != CheckFailed {
+		t.Errorf("final result re-evaluated")
+	}
+
+	q.CheckResults = nil
+	q.ApplyChecks(0, now, true, nil)
+	if q.CheckResults["compr"].Result != CheckPassed {
+		t.Errorf("correct answers should pass")
+	}
+}
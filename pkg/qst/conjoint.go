@@ -0,0 +1,316 @@
+package qst
+
+import (
+	"encoding/csv"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/zew/go-questionnaire/pkg/cfg"
+	"github.com/zew/go-questionnaire/pkg/trl"
+)
+
+// ConjointAttrT is an attribute of the profiles - i.e. price - with its levels
+type ConjointAttrT struct {
+	Name   string  `json:"name"` // column in the export
+	Label  trl.S   `json:"label"`
+	Levels []trl.S `json:"levels"`
+}
+
+// ConjointDesignRowT is one profile of a design file; all indexes are one based
+type ConjointDesignRowT struct {
+	Version int   `json:"version"`
+	Task    int   `json:"task"`
+	Profile int   `json:"profile"`
+	Levels  []int `json:"levels"` // by attribute
+}
+
+// ConjointT is a choice based conjoint experiment;
+// each task shows Profiles profiles side by side;
+// the participant chooses one of them;
+//
+// profiles are drawn randomly per participant - reproducible from the user ID;
+// or taken from Design, with the version assigned by user ID
+type ConjointT struct {
+	Name     string               `json:"name"`
+	Attrs    []ConjointAttrT      `json:"attrs"`
+	Tasks    int                  `json:"tasks"`
+	Profiles int                  `json:"profiles"`
+	Design   []ConjointDesignRowT `json:"design,omitempty"`
+}
+
+// TaskName returns the input name of the choice of task taskIdx - zero based
+func (c ConjointT) TaskName(taskIdx int) string {
+	return fmt.Sprintf("%v_t%02v", c.Name, taskIdx+1)
+}
+
+// versions returns the number of design versions
+func (c ConjointT) versions() int {
+	max := 0
+	for _, row := range c.Design {
+		if row.Version > max {
+			max = row.Version
+		}
+	}
+	return max
+}
+
+// draw returns the levels of all tasks for the user;
+// [task][profile][attribute] - one based level indexes
+func (c ConjointT) draw(userID int) [][][]int {
+
+	h := fnv.New32a()
+	h.Write([]byte(c.Name))
+	seed := int64(userID) + int64(h.Sum32())
+
+	ret := make([][][]int, c.Tasks)
+	for t := range ret {
+		ret[t] = make([][]int, c.Profiles)
+	}
+
+	if vs := c.versions(); vs > 0 {
+		version := int(seed%int64(vs)+int64(vs))%vs + 1
+		for _, row := range c.Design {
+			if row.Version == version {
+				ret[row.Task-1][row.Profile-1] = row.Levels
+			}
+		}
+		return ret
+	}
+
+	gen := rand.New(rand.NewSource(seed))
+	for t := range ret {
+		for p := range ret[t] {
+			// prevent identical profiles within a task
+			for try := 0; try < 20; try++ {
+				lvls := make([]int, len(c.Attrs))
+				for a, attr := range c.Attrs {
+					lvls[a] = gen.Intn(len(attr.Levels)) + 1
+				}
+				ret[t][p] = lvls
+				if !c.duplicate(ret[t][:p], lvls) {
+					break
+				}
+			}
+		}
+	}
+	return ret
+}
+
+func (c ConjointT) duplicate(profiles [][]int, lvls []int) bool {
+	for _, other := range profiles {
+		if fmt.Sprint(other) == fmt.Sprint(lvls) {
+			return true
+		}
+	}
+	return false
+}
+
+// ConjointByName returns the conjoint experiment; or false
+func (q *QuestionnaireT) ConjointByName(name string) (ConjointT, bool) {
+	for _, c := range q.Conjoints {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return ConjointT{}, false
+}
+
+// ConjointLevels returns the levels shown in task taskIdx - [profile][attribute];
+// they are drawn once for all tasks and stored in q.ConjointShown
+func (q *QuestionnaireT) ConjointLevels(c ConjointT, taskIdx int) [][]int {
+	if lvls, ok := q.ConjointShown[c.TaskName(taskIdx)]; ok {
+		return lvls
+	}
+	if q.ConjointShown == nil {
+		q.ConjointShown = map[string][][]int{}
+	}
+	for t, lvls := range c.draw(q.UserIDInt()) {
+		q.ConjointShown[c.TaskName(t)] = lvls
+	}
+	return q.ConjointShown[c.TaskName(taskIdx)]
+}
+
+// AddConjointTask adds task taskIdx of c to page p;
+// a table of the profiles - followed by a radio for each profile;
+// c is registered with the questionnaire on first call
+func (q *QuestionnaireT) AddConjointTask(p *pageT, c ConjointT, taskIdx int, validator string) *groupT {
+
+	if c.Profiles == 0 {
+		c.Profiles = 2
+	}
+	if _, ok := q.ConjointByName(c.Name); !ok {
+		q.Conjoints = append(q.Conjoints, c)
+	}
+
+	gr := p.AddGroup()
+	gr.Cols = float32(c.Profiles)
+
+	inp := gr.AddInput()
+	inp.Type = "dyn-textblock"
+	inp.DynamicFunc = "ConjointTask"
+	inp.DynamicFuncParamset = fmt.Sprintf("%v,%v", c.Name, taskIdx)
+	inp.ColSpan = gr.Cols
+
+	for i := 0; i < c.Profiles; i++ {
+		rad := gr.AddInput()
+		rad.Type = "radio"
+		rad.Name = c.TaskName(taskIdx)
+		rad.ValueRadio = fmt.Sprint(i + 1)
+		rad.Validator = validator
+		rad.ColSpan = 1
+		rad.ColSpanLabel = 1
+		rad.ColSpanControl = 1
+		rad.Label = trl.S{}
+		for _, lc := range cfg.Get().LangCodes {
+			rad.Label[lc] = fmt.Sprintf(cfg.Get().Mp["conjoint_option"].TrSilent(lc), i+1)
+		}
+		rad.ControlFirst()
+	}
+	return gr
+}
+
+// ConjointTask renders the profiles of a task as table;
+// paramSet is "name,taskIdx"
+func ConjointTask(q *QuestionnaireT, inp *inputT, paramSet string) (string, error) {
+
+	parts := strings.Split(paramSet, ",")
+	if len(parts) != 2 {
+		return "", fmt.Errorf("conjoint: paramset %q must be name,taskIdx", paramSet)
+	}
+	c, ok := q.ConjointByName(strings.TrimSpace(parts[0]))
+	if !ok {
+		return "", fmt.Errorf("conjoint: no experiment %q", parts[0])
+	}
+	taskIdx, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil || taskIdx < 0 || taskIdx >= c.Tasks {
+		return "", fmt.Errorf("conjoint %v: invalid task %q", c.Name, parts[1])
+	}
+
+	lc := q.LangCode
+	lvls := q.ConjointLevels(c, taskIdx)
+
+	w := &strings.Builder{}
+	fmt.Fprint(w, "<table class='conjoint'>\n<tr><th></th>")
+	for p := range lvls {
+		fmt.Fprintf(w, "<th>%v</th>", fmt.Sprintf(cfg.Get().Mp["conjoint_option"].TrSilent(lc), p+1))
+	}
+	fmt.Fprint(w, "</tr>\n")
+	for a, attr := range c.Attrs {
+		fmt.Fprintf(w, "<tr><td>%v</td>", attr.Label.TrSilent(lc))
+		for _, prof := range lvls {
+			lbl := ""
+			if a < len(prof) && prof[a] > 0 && prof[a] <= len(attr.Levels) {
+				lbl = attr.Levels[prof[a]-1].TrSilent(lc)
+			}
+			fmt.Fprintf(w, "<td>%v</td>", lbl)
+		}
+		fmt.Fprint(w, "</tr>\n")
+	}
+	fmt.Fprint(w, "</table>\n")
+	return w.String(), nil
+}
+
+// ParseConjointDesign reads a design file into c.Design;
+// CSV with header version, task, profile - and the attribute names in any order;
+// comma or semicolon separated; levels are one based
+func ParseConjointDesign(r io.Reader, c *ConjointT) error {
+
+	rdr := csv.NewReader(r)
+	rdr.Comma = ';'
+	rdr.FieldsPerRecord = -1
+	recs, err := rdr.ReadAll()
+	if err != nil {
+		return fmt.Errorf("conjoint design for %v: %w", c.Name, err)
+	}
+	if len(recs) > 0 && len(recs[0]) == 1 {
+		for i := range recs {
+			recs[i] = strings.Split(recs[i][0], ",")
+		}
+	}
+	if len(recs) < 2 {
+		return fmt.Errorf("conjoint design for %v: no rows", c.Name)
+	}
+
+	cols := map[string]int{}
+	for i, hdr := range recs[0] {
+		cols[strings.ToLower(strings.TrimSpace(hdr))] = i
+	}
+	need := []string{"version", "task", "profile"}
+	for _, attr := range c.Attrs {
+		need = append(need, strings.ToLower(attr.Name))
+	}
+	for _, col := range need {
+		if _, ok := cols[col]; !ok {
+			return fmt.Errorf("conjoint design for %v: column %v missing", c.Name, col)
+		}
+	}
+
+	c.Design = nil
+	for lineIdx, rec := range recs[1:] {
+		vals := make([]int, len(need))
+		for i, col := range need {
+			idx := cols[col]
+			if idx >= len(rec) {
+				return fmt.Errorf("conjoint design for %v: line %v too short", c.Name, lineIdx+2)
+			}
+			vals[i], err = strconv.Atoi(strings.TrimSpace(rec[idx]))
+			if err != nil {
+				return fmt.Errorf("conjoint design for %v: line %v column %v: %w", c.Name, lineIdx+2, col, err)
+			}
+		}
+		c.Design = append(c.Design, ConjointDesignRowT{
+			Version: vals[0],
+			Task:    vals[1],
+			Profile: vals[2],
+			Levels:  vals[3:],
+		})
+	}
+	return nil
+}
+
+// validateConjoints is part of Validate()
+func (q *QuestionnaireT) validateConjoints() error {
+	names := map[string]bool{}
+	for _, c := range q.Conjoints {
+		if c.Name == "" || names[c.Name] {
+			return fmt.Errorf("conjoint name '%v' empty or not unique", c.Name)
+		}
+		names[c.Name] = true
+		if c.Tasks < 1 || c.Profiles < 2 || len(c.Attrs) == 0 {
+			return fmt.Errorf("conjoint %v - needs tasks, two profiles and attributes", c.Name)
+		}
+		for _, attr := range c.Attrs {
+			if attr.Name == "" || len(attr.Levels) < 2 {
+				return fmt.Errorf("conjoint %v - attribute '%v' needs a name and two levels", c.Name, attr.Name)
+			}
+		}
+		covered := map[string]bool{}
+		for _, row := range c.Design {
+			if row.Task < 1 || row.Task > c.Tasks || row.Profile < 1 || row.Profile > c.Profiles || row.Version < 1 {
+				return fmt.Errorf("conjoint %v - design row %+v out of range", c.Name, row)
+			}
+			if len(row.Levels) != len(c.Attrs) {
+				return fmt.Errorf("conjoint %v - design row %+v - %v levels for %v attributes", c.Name, row, len(row.Levels), len(c.Attrs))
+			}
+			for a, lvl := range row.Levels {
+				if lvl < 1 || lvl > len(c.Attrs[a].Levels) {
+					return fmt.Errorf("conjoint %v - design row %+v - level of %v out of range", c.Name, row, c.Attrs[a].Name)
+				}
+			}
+			covered[fmt.Sprint(row.Version, row.Task, row.Profile)] = true
+		}
+		if len(covered) != c.versions()*c.Tasks*c.Profiles {
+			return fmt.Errorf("conjoint %v - design does not cover all tasks and profiles of all versions", c.Name)
+		}
+		for t := 0; t < c.Tasks; t++ {
+			if q.ByName(c.TaskName(t)) == nil {
+				return fmt.Errorf("conjoint %v - task %v not added", c.Name, t+1)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package qst
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/zew/go-questionnaire/pkg/cfg"
+	"github.com/zew/go-questionnaire/pkg/trl"
+)
+
+func TestConjointT(t *testing.T) {
+
+	cfg.LoadFakeConfigForTests()
+
+	c := ConjointT{
+		Name:     "cj",
+		Tasks:    3,
+		Profiles: 2,
+		Attrs: []ConjointAttrT{
+			{Name: "price", Levels: []trl.S{{"en": "10"}, {"en": "20"}, {"en": "30"}}},
+			{Name: "brand", Levels: []trl.S{{"en": "A"}, {"en": "B"}}},
+		},
+	}
+
+	newQ := func(userID string) *QuestionnaireT {
+		q := &QuestionnaireT{UserID: userID, LangCode: "en"}
+		for i := 0; i < c.Tasks; i++ {
+			q.AddConjointTask(q.AddPage(), c, i, "")
+		}
+		return q
+	}
+
+	q1 := newQ("1001")
+	if err := q1.validateConjoints(); err != nil {
+		t.Fatal(err)
+	}
+	lvls := q1.ConjointLevels(c, 1)
+	if len(lvls) != 2 || fmt.Sprint(lvls[0]) == fmt.Sprint(lvls[1]) {
+		t.Errorf("profiles missing or identical: %v", lvls)
+	}
+	if len(q1.ConjointShown) != c.Tasks {
+		t.Errorf("all tasks should be stored: %v", q1.ConjointShown)
+	}
+	if fmt.Sprint(newQ("1001").ConjointLevels(c, 1)) != fmt.Sprint(lvls) {
+		t.Errorf("levels not reproducible")
+	}
+	html, err := ConjointTask(q1, nil, "cj,1")
+	if err != nil || !strings.Contains(html, "<table class='conjoint'>") {
+		t.Errorf("rendering failed: %v", err)
+	}
+
+	design := `version;task;profile;brand;price
+1;1;1;1;1
+1;1;2;2;3
+1;2;1;1;2
+1;2;2;2;2
+1;3;1;2;1
+1;3;2;1;3
+`
+	if err := ParseConjointDesign(strings.NewReader(design), &c); err != nil {
+		t.Fatal(err)
+	}
+	q2 := newQ("1002")
+	if err := q2.validateConjoints(); err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(q2.ConjointLevels(c, 0)); got != "[[1 1] [3 2]]" {
+		t.Errorf("design levels - got %v", got)
+	}
+}
@@ -0,0 +1,145 @@
+package qst
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/zew/go-questionnaire/pkg/cfg"
+)
+
+// consentInput is the name of the checkbox on the consent page
+const consentInput = "consent"
+
+// ConsentT configures the consent page of a survey;
+// Markdown is the consent text from content/<site>/<lang>/ - see RenderStaticContentInner();
+// the file name should contain the version - i.e. "consent-2022-05.md";
+// amended texts are published under a new name
+type ConsentT struct {
+	Page     int    `json:"page"`
+	Markdown string `json:"markdown"`
+}
+
+// ConsentRecordT records the consent of a participant;
+// Hash identifies the exact text shown
+type ConsentRecordT struct {
+	Markdown string    `json:"markdown"`
+	LangCode string    `json:"lang_code"`
+	Hash     string    `json:"hash"` // SHA256 of the rendered text - hex
+	Accepted time.Time `json:"accepted"`
+}
+
+// AddConsentPage adds a page with the consent text and a checkbox;
+// navigation beyond this page requires consent;
+// see ApplyConsent()
+func (q *QuestionnaireT) AddConsentPage(c ConsentT) *pageT {
+
+	p := q.AddPage()
+	c.Page = len(q.Pages) - 1
+	q.Consent = &c
+
+	gr := p.AddGroup()
+	gr.Cols = 1
+
+	inp := gr.AddInput()
+	inp.Type = "dyn-textblock"
+	inp.DynamicFunc = "ConsentText"
+	inp.DynamicFuncParamset = c.Markdown
+	inp.ColSpan = 1
+
+	inp = gr.AddInput()
+	inp.Type = "checkbox"
+	inp.Name = consentInput
+	inp.Label = cfg.Get().Mp["consent_accept"]
+	inp.Validator = "must"
+	inp.ColSpan = 1
+	inp.ColSpanLabel = 1
+	inp.ColSpanControl = 6
+	inp.ControlFirst()
+
+	return p
+}
+
+// consentText renders the consent text in the language of the participant
+func (q *QuestionnaireT) consentText() (string, error) {
+	w := &strings.Builder{}
+	err := RenderStaticContentInner(w, q.Consent.Markdown, q.Survey.Type, q.LangCode)
+	return w.String(), err
+}
+
+// ConsentText renders the consent text;
+// after consent, date and a link for withdrawal are appended;
+// paramSet is the markdown file
+func ConsentText(q *QuestionnaireT, inp *inputT, paramSet string) (string, error) {
+	if q.Consent == nil {
+		return "", fmt.Errorf("consent text: no consent configured")
+	}
+	txt, err := q.consentText()
+	if err != nil {
+		return "", err
+	}
+	if q.ConsentGiven != nil {
+		txt += fmt.Sprintf(
+			"<p>%v <a href='%v'>%v</a></p>\n",
+			fmt.Sprintf(cfg.Get().Mp["consent_given"].Tr(q.LangCode), q.ConsentGiven.Accepted.Format("02.01.2006 15:04")),
+			cfg.Pref("/consent-withdraw"),
+			cfg.Get().Mp["consent_withdraw"].Tr(q.LangCode),
+		)
+	}
+	return txt, nil
+}
+
+// ApplyConsent records the consent upon submitting the consent page;
+// without consent, participants are kept on the consent page;
+// to be called after q.CurrPage has been set
+func (q *QuestionnaireT) ApplyConsent(prevPage int, now time.Time) error {
+
+	if q.Consent == nil {
+		return nil
+	}
+
+	if q.ConsentGiven == nil && prevPage == q.Consent.Page {
+		if inp := q.ByName(consentInput); inp != nil && inp.Response != "" {
+			txt, err := q.consentText()
+			if err != nil {
+				q.CurrPage = q.Consent.Page
+				return fmt.Errorf("consent of user %v not recorded: %w", q.UserID, err)
+			}
+			q.ConsentGiven = &ConsentRecordT{
+				Markdown: q.Consent.Markdown,
+				LangCode: q.LangCode,
+				Hash:     fmt.Sprintf("%x", sha256.Sum256([]byte(txt))),
+				Accepted: now,
+			}
+			log.Printf("user %v consented to %v - %v", q.UserID, q.Consent.Markdown, q.ConsentGiven.Hash)
+		}
+	}
+
+	if q.ConsentGiven == nil && q.CurrPage > q.Consent.Page {
+		q.CurrPage = q.Consent.Page
+	}
+	return nil
+}
+
+// validateConsent is part of Validate()
+func (q *QuestionnaireT) validateConsent() error {
+	if q.Consent == nil {
+		return nil
+	}
+	if q.Consent.Markdown == "" {
+		return fmt.Errorf("consent - no markdown file")
+	}
+	if q.Consent.Page < 0 || q.Consent.Page > len(q.Pages)-1 {
+		return fmt.Errorf("consent - page %v out of range", q.Consent.Page)
+	}
+	for _, gr := range q.Pages[q.Consent.Page].Groups {
+		for _, inp := range gr.Inputs {
+			if inp.Name == consentInput {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("consent - checkbox %v not on page %v", consentInput, q.Consent.Page)
+}
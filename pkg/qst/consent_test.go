@@ -0,0 +1,42 @@
+package qst
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zew/go-questionnaire/pkg/cfg"
+)
+
+func TestQuestionnaireT_ApplyConsent(t *testing.T) {
+
+	cfg.LoadFakeConfigForTests()
+
+	q := &QuestionnaireT{LangCode: "en", UserID: "1"}
+	q.AddConsentPage(ConsentT{Markdown: "consent-does-not-exist.md"})
+	q.AddPage()
+	q.AddPage()
+	if err := q.validateConsent(); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	// jumping ahead without consent
+	q.CurrPage = 2
+	if err := q.ApplyConsent(1, now); err != nil || q.CurrPage != 0 {
+		t.Errorf("participant should be kept on consent page: %v - page %v", err, q.CurrPage)
+	}
+
+	// consent text missing - consent cannot be recorded
+	q.ByName(consentInput).Response = "on"
+	q.CurrPage = 1
+	if err := q.ApplyConsent(0, now); err == nil || q.CurrPage != 0 || q.ConsentGiven != nil {
+		t.Errorf("consent should not be recorded without text: %v - page %v", err, q.CurrPage)
+	}
+
+	// recorded consent is kept
+	q.ConsentGiven = &ConsentRecordT{Markdown: "consent-does-not-exist.md", Hash: "abc", Accepted: now}
+	q.CurrPage = 2
+	if err := q.ApplyConsent(1, now.Add(time.Hour)); err != nil || q.CurrPage != 2 || !q.ConsentGiven.Accepted.Equal(now) {
+		t.Errorf("consent given: %v - page %v - %+v", err, q.CurrPage, q.ConsentGiven)
+	}
+}
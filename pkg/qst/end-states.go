@@ -0,0 +1,140 @@
+package qst
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// end states of a questionnaire - QuestionnaireT.EndState;
+// empty for questionnaires not yet ended - or ended before end states existed
+const (
+	EndComplete        = "complete"
+	EndScreenedOut     = "screened_out"
+	EndOverQuota       = "over_quota"
+	EndAttentionFailed = "attention_failed"
+	EndTimedOut        = "timed_out"
+)
+
+// EndStatus maps end states to the status column of the export;
+// 0 - no page finished, 1 - some pages finished - are set by the transferrer
+var EndStatus = map[string]string{
+	EndComplete:        "2",
+	EndOverQuota:       "3",
+	EndScreenedOut:     "4",
+	EndAttentionFailed: "5",
+	EndTimedOut:        "6",
+}
+
+// EndError can be wrapped into the error of a validator;
+// instead of a validation error message,
+// the questionnaire is ended with State
+type EndError struct {
+	State string
+}
+
+// Error implements the errors.Error interface
+func (ee EndError) Error() string {
+	return "questionnaire ended: " + ee.State
+}
+
+// asEndError finds an EndError in the chain of err -
+// wrapped as value or as pointer
+func asEndError(err error) (EndError, bool) {
+	var ee EndError
+	if errors.As(err, &ee) {
+		return ee, true
+	}
+	var pee *EndError
+	if errors.As(err, &pee) && pee != nil {
+		return *pee, true
+	}
+	return EndError{}, false
+}
+
+// EndRuleT ends the questionnaire with State,
+// if all Responses match, when the participant submits page Page
+type EndRuleT struct {
+	State     string            `json:"state"`
+	Page      int               `json:"page"`
+	Responses map[string]string `json:"responses"` // input name => value
+}
+
+// matchResponses is true, if all responses of q match conds
+func (q *QuestionnaireT) matchResponses(conds map[string]string) bool {
+	for name, v := range conds {
+		inp := q.ByName(name)
+		if inp == nil || inp.Response != v {
+			return false
+		}
+	}
+	return true
+}
+
+// End closes the questionnaire with state;
+// participant is moved to the first page with EndState == state - if any;
+// an earlier end state is never overwritten
+func (q *QuestionnaireT) End(state string, now time.Time) {
+	if q.EndState != "" {
+		return
+	}
+	q.EndState = state
+	if q.ClosingTime.IsZero() {
+		q.ClosingTime = now
+	}
+	if idx := q.EndPage(state); idx > -1 {
+		q.CurrPage = idx
+	}
+}
+
+// EndPage returns the index of the first page for state; or -1
+func (q *QuestionnaireT) EndPage(state string) int {
+	for i, pg := range q.Pages {
+		if pg.EndState == state {
+			return i
+		}
+	}
+	return -1
+}
+
+// ApplyEndRules is called after submitting page prevPage
+// and after q.CurrPage has been set;
+// end rules for prevPage are evaluated;
+// reaching a page with EndState ends the questionnaire as well
+func (q *QuestionnaireT) ApplyEndRules(prevPage int, now time.Time) {
+	if q.EndState != "" {
+		return
+	}
+	for _, rule := range q.EndRules {
+		if rule.Page == prevPage && q.matchResponses(rule.Responses) {
+			q.End(rule.State, now)
+			return
+		}
+	}
+	if q.CurrPage > -1 && q.CurrPage < len(q.Pages) && q.Pages[q.CurrPage].EndState != "" {
+		q.End(q.Pages[q.CurrPage].EndState, now)
+	}
+}
+
+// validateEndStates is part of Validate()
+func (q *QuestionnaireT) validateEndStates() error {
+	for i, pg := range q.Pages {
+		if _, ok := EndStatus[pg.EndState]; pg.EndState != "" && !ok {
+			return fmt.Errorf("page %v - unknown end state %q", i, pg.EndState)
+		}
+	}
+	for i, rule := range q.EndRules {
+		if _, ok := EndStatus[rule.State]; !ok {
+			return fmt.Errorf("end rule %v - unknown end state %q", i, rule.State)
+		}
+		if rule.Page < 0 || rule.Page > len(q.Pages)-1 {
+			return fmt.Errorf("end rule %v - page %v out of range", i, rule.Page)
+		}
+		for name := range rule.Responses {
+			if q.ByName(name) == nil {
+				return fmt.Errorf("end rule %v - condition on unknown input %v", i, name)
+			}
+		}
+	}
+	return nil
+}
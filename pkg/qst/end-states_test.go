@@ -0,0 +1,69 @@
+package qst
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestQuestionnaireT_ApplyEndRules(t *testing.T) {
+
+	newQ := func(age string) *QuestionnaireT {
+		q := newTestQ(4, "minor")
+		q.ByName("minor").Response = age
+		q.Pages[3].EndState = EndScreenedOut
+		q.EndRules = []EndRuleT{{State: EndScreenedOut, Page: 0, Responses: map[string]string{"minor": "yes"}}}
+		q.CurrPage = 1
+		return q
+	}
+	now := time.Date(2022, 5, 3, 10, 0, 0, 0, time.UTC)
+
+	q := newQ("no")
+	q.ApplyEndRules(0, now)
+	if q.EndState != "" || !q.ClosingTime.IsZero() || q.CurrPage != 1 {
+		t.Errorf("rule should not apply: %q", q.EndState)
+	}
+
+	q = newQ("yes")
+	q.ApplyEndRules(0, now)
+	if q.EndState != EndScreenedOut || !q.ClosingTime.Equal(now) || q.CurrPage != 3 {
+		t.Errorf("rule should apply: %q page %v", q.EndState, q.CurrPage)
+	}
+
+	// reaching the end page
+	q = newQ("no")
+	q.CurrPage = 3
+	q.ApplyEndRules(2, now)
+	if q.EndState != EndScreenedOut {
+		t.Errorf("end page should end questionnaire")
+	}
+
+	// no overwriting
+	q.End(EndComplete, now.Add(time.Hour))
+	if q.EndState != EndScreenedOut || !q.ClosingTime.Equal(now) {
+		t.Errorf("end state overwritten: %q", q.EndState)
+	}
+}
+
+func TestQuestionnaireT_EndError(t *testing.T) {
+
+	validators["testEnd"] = func(q *QuestionnaireT, inp *inputT) error {
+		if inp.Response == "value" {
+			return fmt.Errorf("screened: %w", EndError{State: EndScreenedOut})
+		}
+		return fmt.Errorf("screened: %w", &EndError{State: EndScreenedOut})
+	}
+	defer delete(validators, "testEnd")
+
+	for _, resp := range []string{"value", "pointer"} {
+		q := newTestQ(3, "minor")
+		q.ByName("minor").Validator = "testEnd"
+		q.ByName("minor").Response = resp
+		q.Pages[2].EndState = EndScreenedOut
+		q.CurrPage = 1
+		q.ValidateResponseData(0, "en")
+		if q.EndState != EndScreenedOut || q.CurrPage != 2 || q.ByName("minor").ErrMsg != "" {
+			t.Errorf("%v: end error should end the questionnaire: %q page %v", resp, q.EndState, q.CurrPage)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package qst
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zew/go-questionnaire/pkg/cfg"
+	"github.com/zew/go-questionnaire/pkg/chart"
+	"github.com/zew/go-questionnaire/pkg/cloudio"
+	"github.com/zew/go-questionnaire/pkg/trl"
+)
+
+// Participant feedback - comparing own answers with the aggregate of a wave.
+//
+// paramSet is the input name - optionally followed by ",previous"
+// to compare against the previous wave;
+// feedback is only shown after the participant has finished.
+
+// feedbackMinN - aggregates of fewer responses are not shown;
+// they could reveal individual answers
+const feedbackMinN = 5
+
+// feedbackTTL - aggregates are recomputed after this duration
+const feedbackTTL = 15 * time.Minute
+
+// aggregateT contains the finished responses of one input
+type aggregateT struct {
+	Numbers []float64      // sorted
+	Counts  map[string]int // radio and dropdown values
+	N       int
+}
+
+// waveAggregatesT is locked while being computed;
+// concurrent requests for the same wave wait - other waves are not blocked
+type waveAggregatesT struct {
+	sync.Mutex
+	computed time.Time
+	byName   map[string]*aggregateT
+}
+
+// feedbackCache is keyed by survey type and wave ID;
+// its lock only guards the map
+var feedbackCache = struct {
+	sync.Mutex
+	mp map[string]*waveAggregatesT
+}{
+	mp: map[string]*waveAggregatesT{},
+}
+
+// eachFinished calls fn for all completed questionnaires of a wave;
+// similar to tf.RetrieveEach() - cyclic dependencies
+func eachFinished(surveyType, waveID string, fn func(q *QuestionnaireT)) error {
+	pth := path.Join(BasePath(), surveyType, waveID)
+	infos, err := cloudio.ReadDir(pth)
+	if err != nil {
+		return fmt.Errorf("could not read directory %v: %w", pth, err)
+	}
+	for _, info := range *infos {
+		if info.IsDir || !strings.HasSuffix(info.Key, ".json") {
+			continue
+		}
+		q, err := Load1(info.Key)
+		if err != nil {
+			log.Printf("skipping %v: %v", info.Key, err)
+			continue
+		}
+		if q.ClosingTime.IsZero() {
+			continue
+		}
+		if q.EndState != "" && q.EndState != EndComplete {
+			continue // screened out
+		}
+		fn(q)
+	}
+	return nil
+}
+
+// waveAggregates collects all finished responses of a wave
+func waveAggregates(surveyType, waveID string) (map[string]*aggregateT, error) {
+
+	key := surveyType + "/" + waveID
+
+	feedbackCache.Lock()
+	wa, ok := feedbackCache.mp[key]
+	if !ok {
+		wa = &waveAggregatesT{}
+		feedbackCache.mp[key] = wa
+	}
+	feedbackCache.Unlock()
+
+	wa.Lock()
+	defer wa.Unlock()
+	if wa.byName != nil && time.Since(wa.computed) < feedbackTTL {
+		return wa.byName, nil
+	}
+
+	byName := map[string]*aggregateT{}
+	err := eachFinished(surveyType, waveID, func(q *QuestionnaireT) {
+		seen := map[string]bool{} // radios share their name
+		for _, pg := range q.Pages {
+			for _, gr := range pg.Groups {
+				for _, inp := range gr.Inputs {
+					if inp.IsLayout() || inp.Response == "" || seen[inp.Name] {
+						continue
+					}
+					seen[inp.Name] = true
+					agg := byName[inp.Name]
+					if agg == nil {
+						agg = &aggregateT{Counts: map[string]int{}}
+						byName[inp.Name] = agg
+					}
+					if inp.Type == "number" {
+						fl, err := strconv.ParseFloat(DelocalizeNumber(inp.Response), 64)
+						if err != nil {
+							continue
+						}
+						agg.Numbers = append(agg.Numbers, fl)
+					} else {
+						agg.Counts[inp.Response]++
+					}
+					agg.N++
+				}
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, agg := range byName {
+		sort.Float64s(agg.Numbers)
+	}
+
+	wa.computed = time.Now()
+	wa.byName = byName
+	log.Printf("feedback: aggregated %v inputs of %v", len(byName), key)
+	return byName, nil
+}
+
+// feedbackParams parses paramSet and returns the input of q,
+// and the aggregate of the current or previous wave;
+// msg is set instead, if no feedback can be shown
+func feedbackParams(q *QuestionnaireT, paramSet string) (inp *inputT, agg *aggregateT, msg string, err error) {
+
+	if q.ClosingTime.IsZero() {
+		return nil, nil, cfg.Get().Mp["feedback_after_closing"].TrSilent(q.LangCode), nil
+	}
+
+	parts := strings.Split(paramSet, ",")
+	name := strings.TrimSpace(parts[0])
+	waveID := q.Survey.WaveID()
+	if len(parts) > 1 && strings.TrimSpace(parts[1]) == "previous" {
+		waveID = q.Survey.PreviousWaveID()
+	}
+
+	inp = q.ByName(name)
+	if inp == nil {
+		return nil, nil, "", fmt.Errorf("feedback: no input %q", name)
+	}
+
+	byName, err := waveAggregates(q.Survey.Type, waveID)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	agg = byName[name]
+	if agg == nil || agg.N < feedbackMinN {
+		return nil, nil, cfg.Get().Mp["feedback_too_few"].TrSilent(q.LangCode), nil
+	}
+	return inp, agg, "", nil
+}
+
+// FeedbackNumber compares the participant's number response
+// with mean and median of all participants;
+// position is the share of participants with a lower response
+func FeedbackNumber(q *QuestionnaireT, inp *inputT, paramSet string) (string, error) {
+
+	own, agg, msg, err := feedbackParams(q, paramSet)
+	if err != nil || msg != "" {
+		return msg, err
+	}
+	if len(agg.Numbers) < feedbackMinN { // i.e. a text input
+		return cfg.Get().Mp["feedback_too_few"].TrSilent(q.LangCode), nil
+	}
+
+	sum := 0.0
+	for _, fl := range agg.Numbers {
+		sum += fl
+	}
+	mean := sum / float64(len(agg.Numbers))
+	median := agg.Numbers[len(agg.Numbers)/2]
+	if len(agg.Numbers)%2 == 0 {
+		median = (agg.Numbers[len(agg.Numbers)/2-1] + median) / 2
+	}
+
+	mp := cfg.Get().Mp
+	lc := q.LangCode
+
+	b := &strings.Builder{}
+	fmt.Fprint(b, "<table class='feedback'>\n")
+	ownStr := "-"
+	if own.Response != "" {
+		ownStr = own.Response // escaped on submit
+	}
+	fmt.Fprintf(b, "<tr><td>%v</td><td>%v</td></tr>\n", mp["feedback_own"].TrSilent(lc), ownStr)
+	fmt.Fprintf(b, "<tr><td>%v</td><td>%v</td></tr>\n", mp["feedback_mean"].TrSilent(lc), chart.Num(lc, 2, mean))
+	fmt.Fprintf(b, "<tr><td>%v</td><td>%v</td></tr>\n", mp["feedback_median"].TrSilent(lc), chart.Num(lc, 2, median))
+	fmt.Fprintf(b, "<tr><td>%v</td><td>%v</td></tr>\n", mp["feedback_n"].TrSilent(lc), len(agg.Numbers))
+	if fl, err := strconv.ParseFloat(DelocalizeNumber(own.Response), 64); err == nil {
+		lower := sort.SearchFloat64s(agg.Numbers, fl) // index of first value >= fl
+		pct := 100 * float64(lower) / float64(len(agg.Numbers))
+		fmt.Fprintf(b, "<tr><td colspan='2'>%v</td></tr>\n", fmt.Sprintf(mp["feedback_position"].TrSilent(lc), pct))
+	}
+	fmt.Fprint(b, "</table>\n")
+
+	// no histogram - sparse bins and the lowest bin edge would show individual answers
+
+	return b.String(), nil
+}
+
+// FeedbackDistribution shows the shares of all options
+// of a radio or dropdown input - marking the participant's choice;
+// followed by a bar chart of the shares
+func FeedbackDistribution(q *QuestionnaireT, inp *inputT, paramSet string) (string, error) {
+
+	own, agg, msg, err := feedbackParams(q, paramSet)
+	if err != nil || msg != "" {
+		return msg, err
+	}
+
+	type optT struct{ key, label string }
+	opts := []optT{}
+	if own.Type == "dropdown" && own.DD != nil {
+		for _, opt := range own.DD.Options {
+			if opt.Key != "" {
+				opts = append(opts, optT{opt.Key, opt.Val.TrSilent(q.LangCode)})
+			}
+		}
+	}
+	if own.Type == "radio" {
+		for _, pg := range q.Pages {
+			for _, gr := range pg.Groups {
+				for _, rad := range gr.Inputs {
+					if rad.Type == "radio" && rad.Name == own.Name {
+						opts = append(opts, optT{rad.ValueRadio, q.LabelCleanse(rad.Label.TrSilent(q.LangCode))})
+					}
+				}
+			}
+		}
+	}
+
+	lc := q.LangCode
+	c := chart.ChartT{YLabel: trl.S{lc: "%"}, Series: []chart.SeriesT{{}}}
+	b := &strings.Builder{}
+	fmt.Fprint(b, "<table class='feedback'>\n")
+	for _, opt := range opts {
+		lbl := opt.label
+		if lbl == "" {
+			lbl = html.EscapeString(opt.key)
+		}
+		mark := ""
+		if opt.key == own.Response {
+			mark = cfg.Get().Mp["feedback_own_choice"].TrSilent(lc)
+		}
+		pct := 100 * float64(agg.Counts[opt.key]) / float64(agg.N)
+		fmt.Fprintf(b,
+			"<tr><td>%v</td><td>%v%%</td><td>%v</td></tr>\n",
+			lbl, chart.Num(lc, 1, pct), mark,
+		)
+		c.Categories = append(c.Categories, trl.S{lc: html.UnescapeString(reportLabel(lbl))}) // chart escapes
+		c.Series[0].Values = append(c.Series[0].Values, pct)
+	}
+	fmt.Fprintf(b, "<tr><td>%v</td><td>%v</td><td></td></tr>\n", cfg.Get().Mp["feedback_n"].TrSilent(lc), agg.N)
+	fmt.Fprint(b, "</table>\n")
+	fmt.Fprint(b, chart.Bar(c, lc))
+
+	return b.String(), nil
+}
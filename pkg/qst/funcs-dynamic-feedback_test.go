@@ -0,0 +1,83 @@
+package qst
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zew/go-questionnaire/pkg/cfg"
+)
+
+// feedbackQ has a number, a text and a radio input
+func feedbackQ(userID, num, txt, rad string) *QuestionnaireT {
+	q := &QuestionnaireT{UserID: userID, LangCode: "en"}
+	q.Survey = SurveyT{Type: "fb", Year: 2022, Month: 5}
+	gr := q.AddPage().AddGroup()
+	inp := gr.AddInput()
+	inp.Type, inp.Name, inp.Response = "number", "forecast", num
+	inp = gr.AddInput()
+	inp.Type, inp.Name, inp.Response = "text", "comment", txt
+	for _, val := range []string{"up", "down"} {
+		inp = gr.AddInput()
+		inp.Type, inp.Name, inp.ValueRadio = "radio", "trend", val
+		if val == rad {
+			inp.Response = rad
+		}
+	}
+	q.ClosingTime = time.Date(2022, 5, 20, 12, 0, 0, 0, time.UTC)
+	return q
+}
+
+func TestFeedback(t *testing.T) {
+
+	cfg.LoadFakeConfigForTests()
+
+	chdirTemp(t)
+
+	rads := []string{"up", "up", "up", "down", "up", "down"}
+	for i, rad := range rads {
+		q := feedbackQ(fmt.Sprint(1000+i), fmt.Sprint(i+1), "text", rad)
+		if i == len(rads)-1 {
+			q.ClosingTime = time.Time{} // unfinished - not counted
+		}
+		if err := q.Save1(path.Join(BasePath(), "fb", "2022-05", q.UserID)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	q := feedbackQ("1002", "3", "text", "up")
+
+	txt, err := FeedbackNumber(q, nil, "forecast")
+	if err != nil || !strings.Contains(txt, "3.00") || !strings.Contains(txt, "<td>5</td>") {
+		t.Errorf("number feedback - mean and median 3 of 5: %v\n%v", err, txt)
+	}
+	q.LangCode = "de"
+	if txt, _ := FeedbackNumber(q, nil, "forecast"); !strings.Contains(txt, "3,00") {
+		t.Errorf("German number feedback should have decimal commas:\n%v", txt)
+	}
+	q.LangCode = "en"
+
+	// text input has enough responses - but no numbers
+	txt, err = FeedbackNumber(q, nil, "comment")
+	if err != nil || txt != cfg.Get().Mp["feedback_too_few"].TrSilent("en") {
+		t.Errorf("text input: %v - %q", err, txt)
+	}
+
+	txt, err = FeedbackDistribution(q, nil, "trend")
+	if err != nil || !strings.Contains(txt, "80.0%") || !strings.Contains(txt, "20.0%") || !strings.Contains(txt, "<svg") {
+		t.Errorf("distribution 4 up - 1 down: %v\n%v", err, txt)
+	}
+
+	// previous wave has no responses
+	txt, err = FeedbackDistribution(q, nil, "trend,previous")
+	if err != nil || txt != cfg.Get().Mp["feedback_too_few"].TrSilent("en") {
+		t.Errorf("previous wave: %v - %q", err, txt)
+	}
+
+	q.ClosingTime = time.Time{}
+	if txt, _ := FeedbackNumber(q, nil, "forecast"); txt != cfg.Get().Mp["feedback_after_closing"].TrSilent("en") {
+		t.Errorf("feedback before closing: %q", txt)
+	}
+}
@@ -32,6 +32,13 @@ var dynFuncs = map[string]dynFuncT{
 	"PatLogos":                       PatLogos,
 	"RenderStaticContent":            RenderStaticContent,
 	"ErrorProxy":                     ErrorProxy,
+	"FeedbackNumber":                 FeedbackNumber,
+	"FeedbackDistribution":           FeedbackDistribution,
+	"ReportLink":                     ReportLink,
+	"ConjointTask":                   ConjointTask,
+	"Vignette":                       Vignette,
+	"Payout":                         Payout,
+	"ConsentText":                    ConsentText,
 }
 
 func isOther(inpName string) bool {
@@ -148,6 +155,7 @@ func PersonalLink(q *QuestionnaireT, inp *inputT, paramSet string) (string, erro
 	if closed {
 		ret = cfg.Get().Mp["finished_by_participant"].Tr(q.LangCode)
 		ret = fmt.Sprintf(ret, q.ClosingTime.Format("02.01.2006 15:04"))
+		ret += "<br>\n" + reportLink(q)
 	} else {
 		ret = cfg.Get().Mp["review_by_personal_link"].Tr(q.LangCode)
 	}
@@ -162,6 +170,7 @@ func PermaLink(q *QuestionnaireT, inp *inputT, paramSet string) (string, error)
 	if closed {
 		ret = cfg.Get().Mp["finished_by_participant"].Tr(q.LangCode)
 		ret = fmt.Sprintf(ret, q.ClosingTime.Format("02.01.2006 15:04"))
+		ret += "<br>\n" + reportLink(q)
 	} else {
 		permaLink, ok := q.Attrs["permalink"]
 		if ok {
@@ -175,6 +184,19 @@ func PermaLink(q *QuestionnaireT, inp *inputT, paramSet string) (string, error)
 	return ret, nil
 }
 
+// reportLink points to the printable report of the participant's answers
+func reportLink(q *QuestionnaireT) string {
+	return fmt.Sprintf(cfg.Get().Mp["report_link"].Tr(q.LangCode), cfg.Pref("/report"))
+}
+
+// ReportLink returns the link to the printable report - after closing
+func ReportLink(q *QuestionnaireT, inp *inputT, paramSet string) (string, error) {
+	if q.ClosingTime.IsZero() {
+		return "", nil
+	}
+	return reportLink(q), nil
+}
+
 // ResponseTextHasEuro yields texts => want to keep € - want to have €
 func ResponseTextHasEuro(q *QuestionnaireT, inp *inputT, paramSet string) (string, error) {
 
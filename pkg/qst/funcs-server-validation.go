@@ -5,6 +5,7 @@ import (
 	"log"
 	"strconv"
 	"strings"
+	"time"
 
 	"errors"
 
@@ -560,6 +561,11 @@ func (q *QuestionnaireT) ValidateResponseData(pageNum int, langCode string) (las
 						if valiFunc, ok := validators[strings.TrimSpace(valiKey)]; ok {
 							err := valiFunc(q, inp)
 							// log.Printf("%-10v %-20s  %-12s  %v", inp.Name, valiKey, inp.Response, err)
+							if endErr, ok := asEndError(err); ok {
+								// not a validation error - questionnaire ends
+								q.End(endErr.State, time.Now().Truncate(time.Second))
+								continue
+							}
 							if err != nil {
 								last = err
 								q.Pages[i1].Groups[i2].Inputs[i3].ErrMsg = err.Error()
@@ -0,0 +1,34 @@
+package qst
+
+import (
+	"os"
+	"testing"
+)
+
+// newTestQ returns a questionnaire with empty pages;
+// text inputs names are added to the first page
+func newTestQ(pages int, names ...string) *QuestionnaireT {
+	q := &QuestionnaireT{LangCode: "en"}
+	for i := 0; i < pages; i++ {
+		q.AddPage()
+	}
+	if len(names) > 0 {
+		gr := q.Pages[0].AddGroup()
+		for _, nm := range names {
+			inp := gr.AddInput()
+			inp.Name = nm
+			inp.Type = "text"
+		}
+	}
+	return q
+}
+
+// chdirTemp changes into a temp dir for the test;
+// the local bucket is ./app-bucket
+func chdirTemp(t *testing.T) {
+	wd, _ := os.Getwd()
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
@@ -5,10 +5,12 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"path"
 	"strings"
+	"sync"
 
 	"github.com/zew/go-questionnaire/pkg/cloudio"
 )
@@ -47,21 +49,31 @@ func Load1(fn string) (*QuestionnaireT, error) {
 	return &q, nil
 }
 
-// Save1 a questionnaire to JSON
-func (q *QuestionnaireT) Save1(fn string) error {
+// marshal q to JSON - with checksum
+func (q *QuestionnaireT) marshal() ([]byte, error) {
 
 	q.MD5 = "md5dummy"
 
 	firstColLeftMostPrefix := " "
 	bts, err := json.MarshalIndent(q, firstColLeftMostPrefix, "\t")
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// The MD5 value is set *after* serialization, through bytes.Replace
 	hsh := md5Str(bts)
 	bts = bytes.Replace(bts, []byte(q.MD5), []byte(hsh), 1) // replace once to save memory
 	q.MD5 = hsh
+	return bts, nil
+}
+
+// Save1 a questionnaire to JSON
+func (q *QuestionnaireT) Save1(fn string) error {
+
+	bts, err := q.marshal()
+	if err != nil {
+		return err
+	}
 
 	saveDir := path.Dir(fn)
 
@@ -93,6 +105,60 @@ func (q *QuestionnaireT) Save1(fn string) error {
 	return nil
 }
 
+// ErrStale signals a save of an outdated questionnaire;
+// the file was saved meanwhile - by another browser tab, another session or the updater
+var ErrStale = errors.New("questionnaire was saved meanwhile")
+
+// saveLocks serializes Save1Versioned() per file - within this process
+var saveLocks = sync.Map{} // file name => *sync.Mutex
+
+// Save1Versioned saves q like Save1 - unless the file has been saved
+// with a newer revision since q was loaded; then ErrStale is returned;
+// on success q.Revision is incremented;
+// Google cloud storage rejects concurrent writes by generation;
+// local files are written atomically - see cloudio.WriteFileIf();
+// local and S3 buckets have no conditional writes -
+// the check is only safe against writers within this process
+func (q *QuestionnaireT) Save1Versioned(fn string) error {
+
+	if !strings.HasSuffix(fn, ".json") {
+		fn += ".json"
+	}
+	mtx, _ := saveLocks.LoadOrStore(fn, &sync.Mutex{})
+	mtx.(*sync.Mutex).Lock()
+	defer mtx.(*sync.Mutex).Unlock()
+
+	bts, gen, err := cloudio.ReadFileGen(fn)
+	if err != nil && !cloudio.IsNotExist(err) {
+		return err
+	}
+	if err == nil {
+		saved := struct {
+			Revision int `json:"revision"`
+		}{}
+		if err := json.Unmarshal(bts, &saved); err != nil {
+			return fmt.Errorf("revision of %v: %w", fn, err)
+		}
+		if saved.Revision > q.Revision {
+			return fmt.Errorf("%v has revision %v - ours is %v: %w", fn, saved.Revision, q.Revision, ErrStale)
+		}
+	}
+
+	q.Revision++
+	bts, err = q.marshal()
+	if err == nil {
+		err = cloudio.WriteFileIf(fn, bytes.NewReader(bts), 0644, gen)
+	}
+	if err != nil {
+		q.Revision--
+		if errors.Is(err, cloudio.ErrConflict) {
+			return fmt.Errorf("%v: %w", err, ErrStale)
+		}
+		return err
+	}
+	return nil
+}
+
 // Md5Str computes the md5 hash of a byte slice.
 func md5Str(buf []byte) string {
 	hasher := sha256.New()
@@ -0,0 +1,35 @@
+package qst
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestQuestionnaireT_Save1Versioned(t *testing.T) {
+
+	chdirTemp(t)
+
+	fn := "responses/test/1234.json"
+	tab1 := &QuestionnaireT{UserID: "1234"}
+	if err := tab1.Save1Versioned(fn); err != nil || tab1.Revision != 1 {
+		t.Fatalf("first save: %v - revision %v", err, tab1.Revision)
+	}
+
+	tab2, err := Load1(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tab2.Save1Versioned(fn); err != nil || tab2.Revision != 2 {
+		t.Errorf("second tab: %v - revision %v", err, tab2.Revision)
+	}
+
+	// first tab is stale now
+	err = tab1.Save1Versioned(fn)
+	if !errors.Is(err, ErrStale) || tab1.Revision != 1 {
+		t.Errorf("want ErrStale - got %v - revision %v", err, tab1.Revision)
+	}
+	saved, _ := Load1(fn)
+	if saved.Revision != 2 {
+		t.Errorf("saved revision %v - want 2", saved.Revision)
+	}
+}
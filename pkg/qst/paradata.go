@@ -0,0 +1,110 @@
+package qst
+
+import (
+	"time"
+)
+
+// ParadataT records how a participant moved through a page;
+// collected in MainH(), exported by the transferrer;
+// purpose: identify speeders and problematic questions
+type ParadataT struct {
+	FirstEntry      time.Time `json:"first_entry,omitempty"`
+	LastEntry       time.Time `json:"last_entry,omitempty"`       // start of the most recent visit
+	TimeOnPage      int       `json:"time_on_page,omitempty"`     // seconds - summed over all visits
+	Visits          int       `json:"visits,omitempty"`           // number of times the page was entered
+	FailedSubmits   int       `json:"failed_submits,omitempty"`   // submits rejected by validation
+	BackNavigations int       `json:"back_navigations,omitempty"` // number of times the participant went back from this page
+	Device          string    `json:"device,omitempty"`           // "mobile" or "desktop" - of the most recent visit
+
+	Elapsed  int  `json:"elapsed,omitempty"`   // pages with time limits: seconds from first entry to the first accepted submit
+	TimedOut bool `json:"timed_out,omitempty"` // pages with time limits: MaxSeconds passed
+
+	InputErrors map[string]int `json:"input_errors,omitempty"` // failed submits per input name
+}
+
+// paradataMaxVisit caps the time counted for a single request;
+// participants leave their browser open for days
+const paradataMaxVisit = time.Hour
+
+func (p *pageT) paradata() *ParadataT {
+	if p.Paradata == nil {
+		p.Paradata = &ParadataT{}
+	}
+	return p.Paradata
+}
+
+// ParadataLeave adds the time since the last entry
+// to the time on page pageIdx;
+// to be called with the page the request was submitted from
+func (q *QuestionnaireT) ParadataLeave(pageIdx int, now time.Time) {
+	if pageIdx < 0 || pageIdx > len(q.Pages)-1 {
+		return
+	}
+	pd := q.Pages[pageIdx].paradata()
+	if pd.LastEntry.IsZero() {
+		return
+	}
+	dur := now.Sub(pd.LastEntry)
+	if dur > paradataMaxVisit {
+		dur = paradataMaxVisit
+	}
+	if dur > 0 {
+		pd.TimeOnPage += int(dur.Round(time.Second).Seconds())
+	}
+	pd.LastEntry = time.Time{}
+}
+
+// ParadataFailedSubmit counts a submit rejected by validation;
+// inputs with error messages are counted separately;
+// to be called after ValidateResponseData()
+func (q *QuestionnaireT) ParadataFailedSubmit(pageIdx int) {
+	if pageIdx < 0 || pageIdx > len(q.Pages)-1 {
+		return
+	}
+	pd := q.Pages[pageIdx].paradata()
+	pd.FailedSubmits++
+	seen := map[string]bool{} // radios share their name
+	for _, gr := range q.Pages[pageIdx].Groups {
+		for _, inp := range gr.Inputs {
+			if inp.ErrMsg == "" || inp.Name == "" || seen[inp.Name] {
+				continue
+			}
+			seen[inp.Name] = true
+			if pd.InputErrors == nil {
+				pd.InputErrors = map[string]int{}
+			}
+			pd.InputErrors[inp.Name]++
+		}
+	}
+}
+
+// ParadataNavigation counts back navigation from prevPage to currPage
+func (q *QuestionnaireT) ParadataNavigation(prevPage, currPage int) {
+	if prevPage < 0 || prevPage > len(q.Pages)-1 {
+		return
+	}
+	if currPage < prevPage {
+		q.Pages[prevPage].paradata().BackNavigations++
+	}
+}
+
+// ParadataEnter records entering page pageIdx;
+// re-rendering the same page - i.e. after a validation error or a language switch -
+// is not counted as a new visit
+func (q *QuestionnaireT) ParadataEnter(pageIdx, prevPage int, now time.Time, mobile bool) {
+	if pageIdx < 0 || pageIdx > len(q.Pages)-1 {
+		return
+	}
+	pd := q.Pages[pageIdx].paradata()
+	if pd.FirstEntry.IsZero() {
+		pd.FirstEntry = now
+	}
+	if pageIdx != prevPage || pd.Visits == 0 {
+		pd.Visits++
+	}
+	pd.LastEntry = now
+	pd.Device = "desktop"
+	if mobile {
+		pd.Device = "mobile"
+	}
+}
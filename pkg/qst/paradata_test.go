@@ -0,0 +1,39 @@
+package qst
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuestionnaireT_Paradata(t *testing.T) {
+
+	q := newTestQ(3)
+	t0 := time.Date(2022, 4, 1, 12, 0, 0, 0, time.UTC)
+	sec := func(s int) time.Time { return t0.Add(time.Duration(s) * time.Second) }
+
+	// request sequence: enter p0; submit p0 with error; p0 => p1; p1 => back to p0; p0 => p1
+	q.ParadataEnter(0, 0, sec(0), false)
+	q.ParadataLeave(0, sec(20))
+	q.ParadataFailedSubmit(0)
+	q.ParadataEnter(0, 0, sec(20), false)
+	q.ParadataLeave(0, sec(30))
+	q.ParadataNavigation(0, 1)
+	q.ParadataEnter(1, 0, sec(30), false)
+	q.ParadataLeave(1, sec(35))
+	q.ParadataNavigation(1, 0)
+	q.ParadataEnter(0, 1, sec(35), true)
+	q.ParadataLeave(0, sec(36).Add(paradataMaxVisit)) // capped
+	q.ParadataEnter(1, 0, sec(40), true)
+
+	pd0 := q.Pages[0].Paradata
+	if pd0.TimeOnPage != 30+int(paradataMaxVisit.Seconds()) || pd0.Visits != 2 || pd0.FailedSubmits != 1 || pd0.BackNavigations != 0 {
+		t.Errorf("page 0 paradata %+v", pd0)
+	}
+	pd1 := q.Pages[1].Paradata
+	if pd1.TimeOnPage != 5 || pd1.Visits != 2 || pd1.BackNavigations != 1 || pd1.Device != "mobile" || !pd1.FirstEntry.Equal(sec(30)) {
+		t.Errorf("page 1 paradata %+v", pd1)
+	}
+	if q.Pages[2].Paradata != nil {
+		t.Errorf("page 2 should have no paradata")
+	}
+}
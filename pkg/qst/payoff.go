@@ -0,0 +1,174 @@
+package qst
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"log"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zew/go-questionnaire/pkg/cfg"
+)
+
+// PayoffT configures the incentive payment of an experimental survey;
+// on completion, one of Decisions is drawn - with a recorded seed -
+// and paid according to Rule
+type PayoffT struct {
+	Rule      string   `json:"rule"`                // key into payoffFuncs - i.e. "mpl" or "response"
+	Decisions []string `json:"decisions,omitempty"` // input names; default for rule mpl: all rows of all multiple price lists
+	ShowUp    float64  `json:"show_up,omitempty"`   // fixed amount - added to the payoff
+	Currency  string   `json:"currency,omitempty"`  // i.e. "€"
+}
+
+// PayoutT records the payment of a participant;
+// Seed reproduces the draws of decision and lottery
+type PayoutT struct {
+	Seed     int64     `json:"seed"`
+	Decision string    `json:"decision"`
+	Amount   float64   `json:"amount"` // including PayoffT.ShowUp
+	Computed time.Time `json:"computed"`
+}
+
+// payoffFuncT returns the payoff for the drawn decision;
+// gen is for lotteries
+type payoffFuncT func(q *QuestionnaireT, decision string, gen *rand.Rand) (float64, error)
+
+var payoffFuncs = map[string]payoffFuncT{
+	"response": payoffResponse,
+	"mpl":      payoffMPL,
+}
+
+// payoffResponse pays the numeric response of the decision input;
+// i.e. the amount kept in an allocation task
+func payoffResponse(q *QuestionnaireT, decision string, gen *rand.Rand) (float64, error) {
+	inp := q.ByName(decision)
+	if inp == nil {
+		return 0, fmt.Errorf("no input %v", decision)
+	}
+	fl, err := strconv.ParseFloat(DelocalizeNumber(inp.Response), 64)
+	if err != nil {
+		return 0, fmt.Errorf("response %q of %v is not a number: %w", inp.Response, decision, err)
+	}
+	return fl, nil
+}
+
+// payoffMPL plays the lottery, which was chosen in the drawn row of a multiple price list
+func payoffMPL(q *QuestionnaireT, decision string, gen *rand.Rand) (float64, error) {
+	m, ok := q.mplByInput(decision)
+	if !ok {
+		return 0, fmt.Errorf("%v is no row of a multiple price list", decision)
+	}
+	rowIdx, err := strconv.Atoi(strings.TrimPrefix(decision, m.Name+"_r"))
+	if err != nil || rowIdx < 1 || rowIdx > len(m.Rows) {
+		return 0, fmt.Errorf("%v is no row of multiple price list %v", decision, m.Name)
+	}
+	row := m.Rows[rowIdx-1]
+	lottery := row.A
+	switch m.Choices(q)[rowIdx-1] {
+	case "A":
+	case "B":
+		lottery = row.B
+	default:
+		return 0, fmt.Errorf("row %v of %v not answered", rowIdx, m.Name)
+	}
+	if gen.Float64() < lottery.P {
+		return lottery.High, nil
+	}
+	return lottery.Low, nil
+}
+
+// payoffDecisions returns the decisions to draw from
+func (q *QuestionnaireT) payoffDecisions() []string {
+	if len(q.Payoff.Decisions) > 0 || q.Payoff.Rule != "mpl" {
+		return q.Payoff.Decisions
+	}
+	decs := []string{}
+	for _, m := range q.MPLs {
+		for i := range m.Rows {
+			decs = append(decs, m.RowName(i))
+		}
+	}
+	return decs
+}
+
+// ComputePayoff draws the paying decision and computes the payout;
+// only for completed questionnaires - and only once
+func (q *QuestionnaireT) ComputePayoff(now time.Time) error {
+
+	if q.Payoff == nil || q.Payout != nil || q.EndState != EndComplete {
+		return nil
+	}
+	fn, ok := payoffFuncs[q.Payoff.Rule]
+	if !ok {
+		return fmt.Errorf("payoff rule %q not registered", q.Payoff.Rule)
+	}
+	decs := q.payoffDecisions()
+	if len(decs) == 0 {
+		return fmt.Errorf("payoff rule %q - no decisions", q.Payoff.Rule)
+	}
+
+	// not from the clock - participants finishing in the same second would get the same draw
+	var seed int64
+	if err := binary.Read(crand.Reader, binary.LittleEndian, &seed); err != nil {
+		return fmt.Errorf("payoff seed for user %v: %w", q.UserID, err)
+	}
+	po, err := q.drawPayout(fn, decs, seed, now)
+	if err != nil {
+		return err
+	}
+	q.Payout = po
+	log.Printf("user %v - decision %v drawn for payment - %.2f %v", q.UserID, po.Decision, po.Amount, q.Payoff.Currency)
+	return nil
+}
+
+// drawPayout draws decision and lottery from seed
+func (q *QuestionnaireT) drawPayout(fn payoffFuncT, decs []string, seed int64, now time.Time) (*PayoutT, error) {
+	po := &PayoutT{Seed: seed, Computed: now}
+	gen := rand.New(rand.NewSource(po.Seed))
+	po.Decision = decs[gen.Intn(len(decs))]
+	amount, err := fn(q, po.Decision, gen)
+	if err != nil {
+		return nil, fmt.Errorf("payoff for user %v: %w", q.UserID, err)
+	}
+	po.Amount = amount + q.Payoff.ShowUp
+	return po, nil
+}
+
+// Payout shows the payout on the final page
+func Payout(q *QuestionnaireT, inp *inputT, paramSet string) (string, error) {
+	lc := q.LangCode
+	if q.Payout == nil || q.Payoff == nil {
+		return cfg.Get().Mp["payout_pending"].TrSilent(lc), nil
+	}
+	amount := fmt.Sprintf("%.2f", q.Payout.Amount)
+	if lc != "en" {
+		amount = strings.Replace(amount, ".", ",", 1)
+	}
+	return fmt.Sprintf(cfg.Get().Mp["payout_result"].TrSilent(lc), amount, q.Payoff.Currency), nil
+}
+
+// validatePayoff is part of Validate()
+func (q *QuestionnaireT) validatePayoff() error {
+	if q.Payoff == nil {
+		return nil
+	}
+	if _, ok := payoffFuncs[q.Payoff.Rule]; !ok {
+		return fmt.Errorf("payoff rule %q not registered", q.Payoff.Rule)
+	}
+	decs := q.payoffDecisions()
+	if len(decs) == 0 {
+		return fmt.Errorf("payoff rule %q - no decisions", q.Payoff.Rule)
+	}
+	for _, dec := range decs {
+		if _, ok := q.mplByInput(dec); ok && q.Payoff.Rule == "mpl" {
+			continue
+		}
+		if q.ByName(dec) == nil {
+			return fmt.Errorf("payoff decision %v - no such input", dec)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package qst
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zew/go-questionnaire/pkg/cfg"
+)
+
+func TestQuestionnaireT_ComputePayoff(t *testing.T) {
+
+	cfg.LoadFakeConfigForTests()
+
+	q := &QuestionnaireT{UserID: "1004", LangCode: "en"}
+	m := MPLT{Name: "hl", Rows: HoltLauryRows()}
+	q.AddMPL(q.AddPage(), m, "")
+	for i := range m.Rows {
+		q.ByName(m.RowName(i)).Response = "A"
+	}
+	q.Payoff = &PayoffT{Rule: "mpl", ShowUp: 5, Currency: "€"}
+	if err := q.validatePayoff(); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2022, 5, 3, 10, 0, 0, 0, time.UTC)
+	if err := q.ComputePayoff(now); err != nil || q.Payout != nil {
+		t.Errorf("no payout before completion: %v", err)
+	}
+
+	q.End(EndComplete, now)
+	if err := q.ComputePayoff(now); err != nil {
+		t.Fatal(err)
+	}
+	po := *q.Payout
+	if po.Amount != 5+2.00 && po.Amount != 5+1.60 {
+		t.Errorf("safe lottery pays 2.00 or 1.60 - got %v", po.Amount)
+	}
+
+	// reproducible from the seed
+	again, err := q.drawPayout(payoffFuncs["mpl"], q.payoffDecisions(), po.Seed, now)
+	if err != nil || *again != po {
+		t.Errorf("payout not reproducible: %+v - %+v", again, po)
+	}
+
+	// same second - different seed
+	q2 := *q
+	q2.Payout = nil
+	if err := q2.ComputePayoff(now); err != nil || q2.Payout.Seed == po.Seed {
+		t.Errorf("seed should not depend on time: %v", err)
+	}
+
+	// computed only once
+	q.ComputePayoff(now.Add(time.Hour))
+	if q.Payout.Seed != po.Seed {
+		t.Errorf("payout recomputed")
+	}
+
+	if txt, _ := Payout(q, nil, ""); !strings.Contains(txt, "€") {
+		t.Errorf("payout text %q", txt)
+	}
+}
@@ -0,0 +1,105 @@
+package qst
+
+import (
+	"fmt"
+	"html"
+
+	"github.com/zew/go-questionnaire/pkg/cfg"
+)
+
+// values for inputT.Prefill
+const (
+	PrefillValue = "value" // previous response becomes the current response - if empty
+	PrefillHint  = "hint"  // previous response is shown next to the input
+)
+
+// HasPrefills is true, if any input wants previous wave responses
+func (q *QuestionnaireT) HasPrefills() bool {
+	for _, pg := range q.Pages {
+		for _, gr := range pg.Groups {
+			for _, inp := range gr.Inputs {
+				if inp.Prefill != "" {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// PrefillFromPrevious copies responses of qPrev into q.PreviousWave
+// for all inputs with Prefill set;
+// for PrefillValue, empty responses of q are set to the previous response;
+// responses already entered are never overwritten.
+//
+// It should be called only once - unless q.PrefillDone;
+// otherwise deliberately cleared responses would be refilled;
+// qPrev may be nil - if there is no previous response file.
+func (q *QuestionnaireT) PrefillFromPrevious(qPrev *QuestionnaireT) {
+
+	q.PrefillDone = true
+	q.PreviousWave = map[string]string{}
+
+	if qPrev == nil {
+		return
+	}
+
+	for _, pg := range q.Pages {
+		for _, gr := range pg.Groups {
+			for _, inp := range gr.Inputs {
+				if inp.Prefill == "" || inp.IsLayout() {
+					continue
+				}
+				prev := qPrev.ByName(inp.Name)
+				if prev == nil || prev.Response == "" {
+					continue
+				}
+				q.PreviousWave[inp.Name] = prev.Response
+				if inp.Prefill == PrefillValue && inp.Response == "" {
+					inp.Response = prev.Response
+				}
+			}
+		}
+	}
+
+}
+
+// previousWaveHint renders the previous response for PrefillHint inputs;
+// radio inputs only get a marker at the previously chosen option
+func (q *QuestionnaireT) previousWaveHint(inp *inputT) string {
+
+	if inp.Prefill != PrefillHint {
+		return ""
+	}
+	prev, ok := q.PreviousWave[inp.Name]
+	if !ok {
+		return ""
+	}
+
+	if inp.Type == "radio" || inp.Type == "checkbox" {
+		if inp.Type == "radio" && prev != inp.ValueRadio {
+			return ""
+		}
+		if inp.Type == "checkbox" && prev != ValSet {
+			return ""
+		}
+		return fmt.Sprintf(
+			" <span class='previous-wave-hint'>%v</span>",
+			cfg.Get().Mp["previous_wave_choice"].TrSilent(q.LangCode),
+		)
+	}
+
+	if inp.Type == "dropdown" && inp.DD != nil {
+		for _, opt := range inp.DD.Options {
+			if opt.Key == prev {
+				prev = opt.Val.TrSilent(q.LangCode)
+				break
+			}
+		}
+	}
+
+	return fmt.Sprintf(
+		" <span class='previous-wave-hint'>%v</span>",
+		fmt.Sprintf(cfg.Get().Mp["previous_wave_hint"].TrSilent(q.LangCode), html.EscapeString(prev)),
+	)
+}
@@ -0,0 +1,66 @@
+package qst
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestQuestionnaireT_PrefillFromPrevious(t *testing.T) {
+
+	names := []string{"val", "hint", "entered", "none"}
+
+	qPrev := newTestQ(1, names...)
+	for _, nm := range names {
+		qPrev.ByName(nm).Response = "prev-" + nm
+	}
+
+	q := newTestQ(1, names...)
+	q.ByName("val").Prefill = PrefillValue
+	q.ByName("hint").Prefill = PrefillHint
+	q.ByName("entered").Prefill = PrefillValue
+	q.ByName("entered").Response = "current"
+	if !q.HasPrefills() {
+		t.Fatalf("HasPrefills() should be true")
+	}
+
+	q.PrefillFromPrevious(qPrev)
+
+	wants := map[string]string{"val": "prev-val", "hint": "", "entered": "current", "none": ""}
+	for nm, want := range wants {
+		if got := q.ByName(nm).Response; got != want {
+			t.Errorf("response %v = %q, want %q", nm, got, want)
+		}
+	}
+	if len(q.PreviousWave) != 3 || q.PreviousWave["hint"] != "prev-hint" {
+		t.Errorf("PreviousWave = %v", q.PreviousWave)
+	}
+
+	// no previous file - prefilling marked as done anyway
+	q = newTestQ(1, names...)
+	q.PrefillFromPrevious(nil)
+	if !q.PrefillDone {
+		t.Errorf("PrefillDone should be set")
+	}
+
+	// the mark survives saving and joining
+	q2, _ := q.Split()
+	bts, err := json.Marshal(q2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	qSplit := &QuestionnaireT{}
+	if err := json.Unmarshal(bts, qSplit); err != nil {
+		t.Fatal(err)
+	}
+	q = newTestQ(1, names...)
+	if err := q.Join(qSplit); err != nil || !q.PrefillDone {
+		t.Errorf("PrefillDone should be set after joining: %v", err)
+	}
+
+	// questionnaires without prefills are not marked
+	bts, _ = json.Marshal(newTestQ(1, names...))
+	if strings.Contains(string(bts), "previous_wave") || strings.Contains(string(bts), "prefill_done") {
+		t.Errorf("empty previous wave fields should be omitted")
+	}
+}
@@ -454,6 +454,8 @@ func (q QuestionnaireT) InputHTMLGrid(pageIdx, grpIdx, inpIdx int, langCode stri
 	// append suffix
 	ctrl = inp.ShortSuffix(ctrl, q.LangCode)
 
+	ctrl += q.previousWaveHint(&inp)
+
 	// error rendering moved to GroupHTMLGridBased
 
 	return ctrl
@@ -106,6 +106,10 @@ type inputT struct {
 	Response   string `json:"response,omitempty"`
 	ValueRadio string `json:"value_radio,omitempty"` // for type = radio
 
+	// Prefill from the participant's response to the previous wave;
+	// PrefillValue or PrefillHint; see PrefillFromPrevious()
+	Prefill string `json:"prefill,omitempty"`
+
 	// depending if
 	// 		inp.Type == "dyn-composite"
 	// 		inp.Type == "dyn-textblock"
@@ -419,6 +423,14 @@ type pageT struct {
 	// SuppressInProgressbar is a weak form of NoNavigation
 	SuppressInProgressbar bool `json:"suppress_in_progressbar,omitempty"`
 
+	// EndState - reaching this page ends the questionnaire; i.e. a screen out page; see end-states.go
+	EndState string `json:"end_state,omitempty"`
+
+	// time limits for experimental tasks - enforced by the server; see time-limits.go
+	MinSeconds    int    `json:"min_seconds,omitempty"`    // submitting forward is rejected before
+	MaxSeconds    int    `json:"max_seconds,omitempty"`    // countdown; then TimeoutAction is taken
+	TimeoutAction string `json:"timeout_action,omitempty"` // TimeoutSubmit (default), TimeoutLock - or an end state such as EndTimedOut
+
 	navigationSequenceNum int // page number in navigation order; dynamically computed in MainH()
 
 	Style *css.StylesResponsive `json:"style,omitempty"`
@@ -428,6 +440,8 @@ type pageT struct {
 	// truncated to second
 	Finished time.Time `json:"finished,omitempty"`
 
+	Paradata *ParadataT `json:"paradata,omitempty"` // pointer, to avoid empty JSON blocks
+
 	Groups []*groupT `json:"groups,omitempty"`
 
 	ValidationFuncName string `json:"validation_func_name,omitempty"` // file name containing javascript validation func template
@@ -495,6 +509,11 @@ type QuestionnaireT struct {
 	CurrPage  int  `json:"curr_page,omitempty"`
 	HasErrors bool `json:"has_errors,omitempty"` // If any response is faulty; set by ValidateReponseData
 
+	// Revision counts the saves of the user file; see Save1Versioned();
+	// SaveConflict is set for one request, after a stale save was merged
+	Revision     int  `json:"revision,omitempty"`
+	SaveConflict bool `json:"-"`
+
 	// ShufflingVariations indicated how many different reshufflings occur;
 	// until repetition; primitive permutation mechanism;
 	// deterministically reordering / reshuffling a set of groups
@@ -522,6 +541,41 @@ type QuestionnaireT struct {
 	AssignVersion    string `json:"assign_version,omitempty"` // default is UserID modulo - other value is "round-robin"
 	VersionEffective int    `json:"version_effective"`        // result of q.Version()
 
+	// PreviousWave holds the responses of the previous wave
+	// for inputs with Prefill; PrefillDone is set, once loaded
+	PreviousWave map[string]string `json:"previous_wave,omitempty"`
+	PrefillDone  bool              `json:"prefill_done,omitempty"`
+
+	// Quotas are checked in MainH(); see quota.go
+	Quotas    []QuotaT `json:"quotas,omitempty"`
+	OverQuota string   `json:"over_quota,omitempty"` // name of the quota, the participant was screened out by
+
+	// EndState records how the questionnaire ended; see end-states.go
+	EndState string     `json:"end_state,omitempty"`
+	EndRules []EndRuleT `json:"end_rules,omitempty"`
+
+	// Checks are attention and comprehension checks; see checks.go
+	Checks       []CheckT                 `json:"checks,omitempty"`
+	CheckResults map[string]*CheckResultT `json:"check_results,omitempty"` // by check name
+
+	// MPLs are multiple price lists; see static-builder-mpl.go
+	MPLs []MPLT `json:"mpls,omitempty"`
+
+	// Conjoints are choice based conjoint experiments; see conjoint.go
+	Conjoints     []ConjointT        `json:"conjoints,omitempty"`
+	ConjointShown map[string][][]int `json:"conjoint_shown,omitempty"` // levels shown by task input name - [profile][attribute]
+
+	// Vignettes are texts with randomized dimensions; see vignette.go
+	Vignettes []VignetteT `json:"vignettes,omitempty"`
+
+	// Payoff configures incentive payments; Payout is computed on completion; see payoff.go
+	Payoff *PayoffT `json:"payoff,omitempty"`
+	Payout *PayoutT `json:"payout,omitempty"`
+
+	// Consent configures the consent page; ConsentGiven records the consent of the participant; see consent.go
+	Consent      *ConsentT       `json:"consent,omitempty"`
+	ConsentGiven *ConsentRecordT `json:"consent_given,omitempty"`
+
 	MaxGroups int `json:"max_groups,omitempty"` //  Max number of groups - a helper value - computed during questionnaire creation - previously used for shuffing of groups.
 
 	Pages []*pageT `json:"pages,omitempty"`
@@ -1031,6 +1085,14 @@ func (q *QuestionnaireT) PageHTML(pageIdx int) (string, error) {
 			cfg.Get().Mp["correct_errors"].Tr(q.LangCode),
 		)
 	}
+	if q.SaveConflict {
+		fmt.Fprintf(w,
+			`<p class="error" id="save-conflict" >%v</p>`,
+			cfg.Get().Mp["save_conflict"].Tr(q.LangCode),
+		)
+	}
+
+	fmt.Fprint(w, q.timeLimitHTML(pageIdx, time.Now()))
 
 	hasHeader := false
 
@@ -1501,7 +1563,9 @@ func cleansePrefixes(ss []string) []string {
 //
 // functions cleanseIdentical(...) and cleansePrefixes(...)
 // are used to clear out redundancies; see documentation.
-func (q *QuestionnaireT) LabelsByInputNames() (lblsByNames map[string]string, keys, lbls []string) {
+//
+// Labels are taken in language langCode.
+func (q *QuestionnaireT) LabelsByInputNames(langCode string) (lblsByNames map[string]string, keys, lbls []string) {
 
 	lblsByNames = map[string]string{} // init return
 
@@ -1531,7 +1595,7 @@ func (q *QuestionnaireT) LabelsByInputNames() (lblsByNames map[string]string, ke
 					}
 
 					for inpUp := countDownInputsFrom; inpUp > -1; inpUp-- {
-						lb := q.Pages[i1].Groups[grUp].Inputs[inpUp].Label.TrSilent("en")
+						lb := q.Pages[i1].Groups[grUp].Inputs[inpUp].Label.TrSilent(langCode)
 						lb = q.LabelCleanse(lb)
 						if lb != "" {
 							if lbl != "" {
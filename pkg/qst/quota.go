@@ -0,0 +1,122 @@
+package qst
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// QuotaT limits the number of finished questionnaires
+// of participants matching Attrs and Responses;
+// i.e. at most 200 completes per federal state and sector
+type QuotaT struct {
+	Name      string            `json:"name"`
+	Attrs     map[string]string `json:"attrs,omitempty"`     // conditions on q.Attrs - from login or profile
+	Responses map[string]string `json:"responses,omitempty"` // conditions on responses - input name => value
+	Target    int               `json:"target"`              // maximum number of finished questionnaires
+
+	Page        int `json:"page"`         // the quota is checked, when the participant reaches this page index
+	ClosingPage int `json:"closing_page"` // over quota participants are shown this page index - usually NoNavigation
+}
+
+// Matches is true, if q satisfies all conditions of the quota
+func (qt QuotaT) Matches(q *QuestionnaireT) bool {
+	for k, v := range qt.Attrs {
+		if q.Attrs[k] != v {
+			return false
+		}
+	}
+	return q.matchResponses(qt.Responses)
+}
+
+// quotaTTL - counts of finished questionnaires are recomputed after this duration
+const quotaTTL = time.Minute
+
+// quotaCountsT is locked while being counted;
+// concurrent requests for the same wave wait - other waves are not blocked
+type quotaCountsT struct {
+	sync.Mutex
+	computed time.Time
+	counts   map[string]int // by quota name
+}
+
+// quotaCache is keyed by survey type and wave ID;
+// its lock only guards the map
+var quotaCache = struct {
+	sync.Mutex
+	mp map[string]*quotaCountsT
+}{
+	mp: map[string]*quotaCountsT{},
+}
+
+// QuotaCounts returns the number of completed questionnaires per quota
+func (q *QuestionnaireT) QuotaCounts() (map[string]int, error) {
+
+	key := q.Survey.Type + "/" + q.Survey.WaveID()
+
+	quotaCache.Lock()
+	qc, ok := quotaCache.mp[key]
+	if !ok {
+		qc = &quotaCountsT{}
+		quotaCache.mp[key] = qc
+	}
+	quotaCache.Unlock()
+
+	qc.Lock()
+	defer qc.Unlock()
+	if qc.counts != nil && time.Since(qc.computed) < quotaTTL {
+		return qc.counts, nil
+	}
+
+	counts := map[string]int{}
+	err := eachFinished(q.Survey.Type, q.Survey.WaveID(), func(qf *QuestionnaireT) {
+		for _, qt := range q.Quotas {
+			if qt.Matches(qf) {
+				counts[qt.Name]++
+			}
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not count quotas: %w", err)
+	}
+
+	qc.computed, qc.counts = time.Now(), counts
+	return counts, nil
+}
+
+// CheckQuotas is called after q.CurrPage has been set;
+// if a quota for the current page is matched and full,
+// the participant is sent to the quota's closing page
+// and the questionnaire is closed;
+// returns true, if the participant was screened out
+func (q *QuestionnaireT) CheckQuotas(now time.Time) bool {
+
+	if len(q.Quotas) == 0 || q.EndState != "" {
+		return false
+	}
+
+	var counts map[string]int
+	for _, qt := range q.Quotas {
+		if qt.Page != q.CurrPage || !qt.Matches(q) {
+			continue
+		}
+		if counts == nil {
+			var err error
+			counts, err = q.QuotaCounts()
+			if err != nil {
+				log.Print(err) // rather let participants in than lock them out
+				return false
+			}
+		}
+		if counts[qt.Name] < qt.Target {
+			continue
+		}
+		log.Printf("user %v over quota %v - %v of %v", q.UserID, qt.Name, counts[qt.Name], qt.Target)
+		q.OverQuota = qt.Name
+		q.End(EndOverQuota, now)
+		q.CurrPage = qt.ClosingPage
+		return true
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package qst
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zew/go-questionnaire/pkg/cfg"
+)
+
+func TestQuestionnaireT_CheckQuotas(t *testing.T) {
+
+	cfg.LoadFakeConfigForTests()
+
+	newQ := func(state string) *QuestionnaireT {
+		q := newTestQ(4, "state")
+		q.Survey = SurveyT{Type: "quotatest", Year: 2022, Month: 5}
+		q.ByName("state").Response = state
+		q.Attrs = map[string]string{"sector": "bank"}
+		q.Quotas = []QuotaT{
+			{Name: "by-bank", Attrs: map[string]string{"sector": "bank"}, Responses: map[string]string{"state": "by"}, Target: 2, Page: 1, ClosingPage: 3},
+		}
+		q.CurrPage = 1
+		return q
+	}
+
+	// counts are cached - no response files needed
+	quotaCache.mp["quotatest/2022-05"] = &quotaCountsT{computed: time.Now(), counts: map[string]int{"by-bank": 2}}
+	now := time.Date(2022, 5, 3, 10, 0, 0, 0, time.UTC)
+
+	q := newQ("be")
+	if q.CheckQuotas(now) || q.CurrPage != 1 {
+		t.Errorf("non matching participant screened out")
+	}
+
+	q = newQ("by")
+	if !q.CheckQuotas(now) || q.CurrPage != 3 || q.OverQuota != "by-bank" || q.EndState != EndOverQuota || !q.ClosingTime.Equal(now) {
+		t.Errorf("matching participant not screened out: page %v, quota %q", q.CurrPage, q.OverQuota)
+	}
+
+	quotaCache.mp["quotatest/2022-05"].counts["by-bank"] = 1
+	q = newQ("by")
+	if q.CheckQuotas(now) {
+		t.Errorf("participant screened out before target reached")
+	}
+}
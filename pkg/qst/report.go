@@ -0,0 +1,152 @@
+package qst
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/zew/go-questionnaire/pkg/cfg"
+)
+
+var reportTags = regexp.MustCompile(`<[^>]*>`)
+
+// reportLabel removes markup from labels;
+// unlike LabelCleanse() it keeps non-english characters
+func reportLabel(s string) string {
+	s = reportTags.ReplaceAllString(s, " ")
+	s = strings.ReplaceAll(s, "&shy;", "")
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// reportAnswer renders the response of inp readable;
+// radio and dropdown values are replaced by their labels
+func (q *QuestionnaireT) reportAnswer(inp *inputT) string {
+
+	lc := q.LangCode
+
+	switch inp.Type {
+	case "checkbox":
+		if inp.Response == ValSet {
+			return cfg.Get().Mp["yes"].TrSilent(lc)
+		}
+		return cfg.Get().Mp["no"].TrSilent(lc)
+	case "radio":
+		for _, pg := range q.Pages {
+			for _, gr := range pg.Groups {
+				for _, rad := range gr.Inputs {
+					if rad.Type == "radio" && rad.Name == inp.Name && rad.ValueRadio == inp.Response {
+						if lbl := reportLabel(rad.Label.TrSilent(lc)); lbl != "" {
+							return lbl
+						}
+					}
+				}
+			}
+		}
+	case "dropdown":
+		if inp.DD != nil {
+			for _, opt := range inp.DD.Options {
+				if opt.Key == inp.Response {
+					return reportLabel(opt.Val.TrSilent(lc))
+				}
+			}
+		}
+	}
+	return strings.ReplaceAll(inp.Response, "\n", "<br>\n") // escaped on submit
+}
+
+// reportQuestion is the input label - or for radios
+// and inputs without label, the preceding text block of the group
+func (q *QuestionnaireT) reportQuestion(gr *groupT, idx int) string {
+	lc := q.LangCode
+	inp := gr.Inputs[idx]
+	if inp.Type != "radio" {
+		if lbl := reportLabel(inp.Label.TrSilent(lc)); lbl != "" {
+			return lbl
+		}
+	}
+	for i := idx - 1; i > -1; i-- {
+		if gr.Inputs[i].Type == "textblock" {
+			if lbl := reportLabel(gr.Inputs[i].Label.TrSilent(lc)); lbl != "" {
+				return lbl
+			}
+		}
+	}
+	return html.EscapeString(inp.Name)
+}
+
+// Report writes a printable HTML document with all answers of the participant;
+// structured by pages; in the participant's language;
+// radios and checkboxes without response are omitted
+func (q *QuestionnaireT) Report(w io.Writer) {
+
+	lc := q.LangCode
+	mp := cfg.Get().Mp
+
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html lang="%v">
+<head>
+	<meta charset="utf-8">
+	<title>%v</title>
+	<style>
+		body  { font-family: sans-serif; max-width: 48rem; margin: 1.5rem auto; line-height: 1.35; }
+		h2    { font-size: 110%%; margin-top: 1.6rem; border-bottom: 1px solid #aaa; }
+		td    { vertical-align: top; padding: 0.2rem 0.6rem 0.2rem 0; }
+		td.answer { font-weight: bold; min-width: 8rem; }
+		.survey-org, .survey-name { font-size: 130%%; }
+		@media print { .no-print { display: none; } }
+	</style>
+</head>
+<body>
+`,
+		lc, html.EscapeString(mp["report_title"].TrSilent(lc)),
+	)
+
+	fmt.Fprintf(w, "<div class='logo-text'>%v</div>\n", q.Survey.TemplateLogoText(lc))
+	fmt.Fprintf(w, "<p>%v</p>\n", html.EscapeString(mp["report_title"].TrSilent(lc)))
+	if !q.ClosingTime.IsZero() {
+		fmt.Fprintf(w, "<p>%v</p>\n", fmt.Sprintf(mp["finished_by_participant"].TrSilent(lc), q.ClosingTime.Format("02.01.2006 15:04")))
+	}
+	fmt.Fprintf(w, "<p class='no-print'><a href='javascript:window.print()'>%v</a></p>\n", mp["report_print"].TrSilent(lc))
+
+	for pageIdx, pg := range q.Pages {
+
+		if !q.IsInNavigation(pageIdx) {
+			continue
+		}
+
+		rows := &strings.Builder{}
+		seen := map[string]bool{} // radios share their name
+		for _, gr := range pg.Groups {
+			for i, inp := range gr.Inputs {
+				if inp.IsLayout() || inp.Type == "hidden" || inp.Response == "" || seen[inp.Name] {
+					continue
+				}
+				if inp.Type == "checkbox" && inp.Response != ValSet {
+					continue
+				}
+				seen[inp.Name] = true
+				fmt.Fprintf(rows, "<tr><td>%v</td><td class='answer'>%v</td></tr>\n",
+					q.reportQuestion(gr, i), q.reportAnswer(inp))
+			}
+		}
+		if rows.Len() == 0 {
+			continue
+		}
+
+		title := reportLabel(pg.Section.TrSilent(lc))
+		if lbl := reportLabel(pg.Label.TrSilent(lc)); lbl != "" {
+			if title != "" {
+				title += " - "
+			}
+			title += lbl
+		}
+		if title == "" {
+			title = reportLabel(pg.Short.TrSilent(lc))
+		}
+		fmt.Fprintf(w, "<h2>%v</h2>\n<table>\n%v</table>\n", title, rows.String())
+	}
+
+	fmt.Fprint(w, "</body>\n</html>\n")
+}
@@ -12,6 +12,7 @@ func (q *QuestionnaireT) Split() (*QuestionnaireT, error) {
 	for i1 := 0; i1 < len(q.Pages); i1++ {
 		p2 := q2.AddPage()
 		p2.Finished = q.Pages[i1].Finished
+		p2.Paradata = q.Pages[i1].Paradata
 		p2.Label = q.Pages[i1].Label // for debugging
 		for i2 := 0; i2 < len(q.Pages[i1].Groups); i2++ {
 			if q.Pages[i1].Groups[i2].ID == "footer" {
@@ -76,6 +77,18 @@ func (q *QuestionnaireT) Join(q2 *QuestionnaireT) error {
 	q.CurrPage = q2.CurrPage
 	q.HasErrors = q2.HasErrors
 	q.VersionEffective = q2.VersionEffective
+	q.OverQuota = q2.OverQuota
+	q.EndState = q2.EndState
+	q.Revision = q2.Revision
+	q.PrefillDone = q2.PrefillDone
+
+	if q2.CheckResults != nil {
+		q.CheckResults = map[string]*CheckResultT{}
+		for k, v := range q2.CheckResults {
+			res := *v
+			q.CheckResults[k] = &res
+		}
+	}
 
 	attrs := map[string]string{}
 	for k, v := range q2.Attrs {
@@ -83,8 +96,32 @@ func (q *QuestionnaireT) Join(q2 *QuestionnaireT) error {
 	}
 	q.Attrs = attrs
 
+	if q2.Payout != nil {
+		po := *q2.Payout
+		q.Payout = &po
+	}
+	if q2.ConsentGiven != nil {
+		cg := *q2.ConsentGiven
+		q.ConsentGiven = &cg
+	}
+
+	if q2.ConjointShown != nil {
+		q.ConjointShown = map[string][][]int{}
+		for k, v := range q2.ConjointShown {
+			q.ConjointShown[k] = v
+		}
+	}
+
+	if q2.PreviousWave != nil {
+		q.PreviousWave = map[string]string{}
+		for k, v := range q2.PreviousWave {
+			q.PreviousWave[k] = v
+		}
+	}
+
 	for i1 := 0; i1 < len(q.Pages); i1++ {
 		q.Pages[i1].Finished = q2.Pages[i1].Finished
+		q.Pages[i1].Paradata = q2.Pages[i1].Paradata
 		// log.Printf("\tSetting q.Pages[%v].Finished to %v", i1, q2.Pages[i1].Finished)
 		for i2 := 0; i2 < len(q.Pages[i1].Groups); i2++ {
 			for i3 := 0; i3 < len(q.Pages[i1].Groups[i2].Inputs); i3++ {
@@ -0,0 +1,285 @@
+package qst
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/zew/go-questionnaire/pkg/cfg"
+	"github.com/zew/go-questionnaire/pkg/trl"
+)
+
+// LotteryT pays High with probability P - and Low otherwise;
+// a sure payoff has P == 1
+type LotteryT struct {
+	P    float64 `json:"p"`
+	High float64 `json:"high"`
+	Low  float64 `json:"low,omitempty"`
+}
+
+// String formats the lottery, i.e. "10 % 2.00 - 90 % 1.60"
+func (l LotteryT) String() string {
+	if l.P >= 1 || l.High == l.Low {
+		return fmt.Sprintf("%.2f", l.High)
+	}
+	return fmt.Sprintf("%v&nbsp;%% %.2f - %v&nbsp;%% %.2f",
+		math.Round(100*l.P), l.High, math.Round(100*(1-l.P)), l.Low)
+}
+
+// utility with constant relative risk aversion r
+func crra(x, r float64) float64 {
+	if math.Abs(1-r) < 1e-9 {
+		return math.Log(x)
+	}
+	return math.Pow(x, 1-r) / (1 - r)
+}
+
+// expected utility of the lottery with constant relative risk aversion r
+func (l LotteryT) eu(r float64) float64 {
+	if l.P >= 1 {
+		return crra(l.High, r)
+	}
+	return l.P*crra(l.High, r) + (1-l.P)*crra(l.Low, r)
+}
+
+// MPLRowT is one row of a multiple price list - a choice between A and B;
+// LabelA, LabelB replace the formatted lotteries
+type MPLRowT struct {
+	A      LotteryT `json:"a"`
+	B      LotteryT `json:"b"`
+	LabelA trl.S    `json:"label_a,omitempty"`
+	LabelB trl.S    `json:"label_b,omitempty"`
+}
+
+// indifference returns the CRRA coefficient, for which A and B have equal expected utility;
+// NaN if either option is preferred for all coefficients in [-5, 5]
+func (row MPLRowT) indifference() float64 {
+	lo, hi := -5.0, 5.0
+	diff := func(r float64) float64 { return row.A.eu(r) - row.B.eu(r) }
+	dLo := diff(lo)
+	if dLo*diff(hi) > 0 {
+		return math.NaN()
+	}
+	for i := 0; i < 60; i++ {
+		mid := (lo + hi) / 2
+		if d := diff(mid); d*dLo > 0 {
+			lo, dLo = mid, d
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
+
+// MPLT is a multiple price list - i.e. a Holt-Laury risk elicitation;
+// rows must be ordered, so that option B becomes more attractive from row to row;
+//
+// by default, each row is a radio input Name_r01, Name_r02... with values "A" and "B";
+// validator mplSingleSwitch rejects switching back from B to A;
+//
+// with SingleSwitch, there is only one radio input Name;
+// its value is the row, from which on B is chosen; len(Rows)+1 for always A
+type MPLT struct {
+	Name         string    `json:"name"`
+	Rows         []MPLRowT `json:"rows"`
+	HeaderA      trl.S     `json:"header_a,omitempty"`
+	HeaderB      trl.S     `json:"header_b,omitempty"`
+	SingleSwitch bool      `json:"single_switch,omitempty"`
+}
+
+// RowName returns the input name of row rowIdx - zero based
+func (m MPLT) RowName(rowIdx int) string {
+	return fmt.Sprintf("%v_r%02v", m.Name, rowIdx+1)
+}
+
+// HoltLauryRows returns the ten rows of Holt and Laury (2002)
+func HoltLauryRows() []MPLRowT {
+	rows := make([]MPLRowT, 10)
+	for i := range rows {
+		p := float64(i+1) / 10
+		rows[i].A = LotteryT{P: p, High: 2.00, Low: 1.60}
+		rows[i].B = LotteryT{P: p, High: 3.85, Low: 0.10}
+	}
+	return rows
+}
+
+// AddMPL adds the multiple price list to page p
+// and registers it with the questionnaire
+func (q *QuestionnaireT) AddMPL(p *pageT, m MPLT, validator string) *groupT {
+
+	q.MPLs = append(q.MPLs, m)
+
+	lbl := func(s trl.S, l LotteryT) trl.S {
+		if !s.Empty() {
+			return s
+		}
+		return trl.S{"en": l.String(), "de": strings.Replace(l.String(), ".", ",", -1)}
+	}
+
+	if m.SingleSwitch {
+		gr := p.AddGroup()
+		gr.Cols = 1
+		for rowIdx, row := range m.Rows {
+			rad := gr.AddInput()
+			rad.Type = "radio"
+			rad.Name = m.Name
+			rad.ValueRadio = fmt.Sprint(rowIdx + 1)
+			rad.Validator = validator
+			rad.ColSpan = 1
+			rad.ColSpanLabel = 1
+			rad.ColSpanControl = 6
+			rad.Label = trl.S{}
+			for _, lc := range cfg.Get().LangCodes {
+				rad.Label[lc] = fmt.Sprintf(cfg.Get().Mp["mpl_switch_at"].TrSilent(lc),
+					rowIdx+1, lbl(row.LabelA, row.A).TrSilent(lc), lbl(row.LabelB, row.B).TrSilent(lc))
+			}
+			rad.ControlFirst()
+		}
+		rad := gr.AddInput()
+		rad.Type = "radio"
+		rad.Name = m.Name
+		rad.ValueRadio = fmt.Sprint(len(m.Rows) + 1)
+		rad.Validator = validator
+		rad.ColSpan = 1
+		rad.ColSpanLabel = 1
+		rad.ColSpanControl = 6
+		rad.Label = cfg.Get().Mp["mpl_never_switch"]
+		rad.ControlFirst()
+		return gr
+	}
+
+	gb := &GridBuilder{}
+	gb.validator = validator
+	gb.AddCol(m.HeaderA, 3, 1)
+	gb.AddCol(m.HeaderB, 3, 1)
+	for rowIdx, row := range m.Rows {
+		sparse := map[int]trl.S{
+			0: lbl(row.LabelA, row.A),
+			1: lbl(row.LabelB, row.B),
+		}
+		gb.AddRadioRow(m.RowName(rowIdx), []string{"A", "B"}, sparse)
+	}
+	gb.cols[0].cells[0].Validator = strings.Trim(validator+";mplSingleSwitch", ";")
+	gb.cols[1].cells[0].Validator = gb.cols[0].cells[0].Validator
+	return p.AddGrid(gb)
+}
+
+// Choices returns "A", "B" or "" for each row
+func (m MPLT) Choices(q *QuestionnaireT) []string {
+	choices := make([]string, len(m.Rows))
+	if m.SingleSwitch {
+		inp := q.ByName(m.Name)
+		if inp == nil {
+			return choices
+		}
+		sw, err := strconv.Atoi(inp.Response)
+		if err != nil {
+			return choices
+		}
+		for i := range choices {
+			choices[i] = "A"
+			if i+1 >= sw {
+				choices[i] = "B"
+			}
+		}
+		return choices
+	}
+	for i := range m.Rows {
+		if inp := q.ByName(m.RowName(i)); inp != nil {
+			choices[i] = inp.Response
+		}
+	}
+	return choices
+}
+
+// MPLResultT summarizes the choices of a participant
+type MPLResultT struct {
+	Complete  bool    // all rows answered
+	SwitchRow int     // first row with choice B - one based; len(Rows)+1 for always A
+	Switches  int     // number of changes between A and B; more than one is inconsistent
+	CRRALow   float64 // interval of the coefficient of constant relative risk aversion; NaN if unbounded or inconsistent
+	CRRAHigh  float64
+}
+
+// Result evaluates the choices of q
+func (m MPLT) Result(q *QuestionnaireT) MPLResultT {
+
+	res := MPLResultT{CRRALow: math.NaN(), CRRAHigh: math.NaN()}
+	choices := m.Choices(q)
+
+	res.Complete = true
+	res.SwitchRow = len(m.Rows) + 1
+	for i, ch := range choices {
+		if ch == "" {
+			res.Complete = false
+			continue
+		}
+		if ch == "B" && res.SwitchRow > len(m.Rows) {
+			res.SwitchRow = i + 1
+		}
+		if i > 0 && choices[i-1] != "" && choices[i-1] != ch {
+			res.Switches++
+		}
+	}
+	if !res.Complete || res.Switches > 1 {
+		return res
+	}
+
+	// A up to row SwitchRow-1 - B from row SwitchRow
+	if idx := res.SwitchRow - 2; idx > -1 {
+		res.CRRALow = m.Rows[idx].indifference()
+	}
+	if idx := res.SwitchRow - 1; idx < len(m.Rows) {
+		res.CRRAHigh = m.Rows[idx].indifference()
+	}
+	return res
+}
+
+// mplByInput returns the multiple price list containing the input
+func (q *QuestionnaireT) mplByInput(name string) (MPLT, bool) {
+	for _, m := range q.MPLs {
+		if strings.HasPrefix(name, m.Name+"_r") || name == m.Name {
+			return m, true
+		}
+	}
+	return MPLT{}, false
+}
+
+func init() {
+	validators["mplSingleSwitch"] = func(q *QuestionnaireT, inp *inputT) error {
+		m, ok := q.mplByInput(inp.Name)
+		if !ok {
+			return nil
+		}
+		if res := m.Result(q); res.Switches > 1 {
+			return fmt.Errorf(cfg.Get().Mp["mpl_multiple_switching"].Tr(q.LangCode))
+		}
+		return nil
+	}
+}
+
+// validateMPLs is part of Validate()
+func (q *QuestionnaireT) validateMPLs() error {
+	names := map[string]bool{}
+	for _, m := range q.MPLs {
+		if m.Name == "" || names[m.Name] {
+			return fmt.Errorf("multiple price list name '%v' empty or not unique", m.Name)
+		}
+		names[m.Name] = true
+		if len(m.Rows) < 2 {
+			return fmt.Errorf("multiple price list %v - at least two rows required", m.Name)
+		}
+		for i, row := range m.Rows {
+			for _, l := range []LotteryT{row.A, row.B} {
+				if l.P < 0 || l.P > 1 {
+					return fmt.Errorf("multiple price list %v - row %v - probability %v", m.Name, i+1, l.P)
+				}
+				if l.High <= 0 || (l.P < 1 && l.Low <= 0) {
+					return fmt.Errorf("multiple price list %v - row %v - payoffs must be positive", m.Name, i+1)
+				}
+			}
+		}
+	}
+	return nil
+}
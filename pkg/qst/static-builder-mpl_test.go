@@ -0,0 +1,61 @@
+package qst
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/zew/go-questionnaire/pkg/cfg"
+)
+
+func TestMPLT_Result(t *testing.T) {
+
+	cfg.LoadFakeConfigForTests()
+
+	q := &QuestionnaireT{LangCode: "en"}
+	m := MPLT{Name: "hl", Rows: HoltLauryRows()}
+	q.AddMPL(q.AddPage(), m, "")
+	if err := q.validateMPLs(); err != nil {
+		t.Fatal(err)
+	}
+
+	tts := []struct {
+		choices  string
+		complete bool
+		switch1  int
+		switches int
+		lo, hi   float64 // NaN as -9
+	}{
+		{"AAAAABBBBB", true, 6, 1, 0.15, 0.41}, // Holt, Laury - table 3
+		{"AAAABBBBBB", true, 5, 1, -0.15, 0.15},
+		{"BBBBBBBBBB", true, 1, 0, -9, -1.71},
+		{"AAAAAAAAAB", true, 10, 1, 1.37, -9},
+		{"AAABABBBBB", true, 4, 3, -9, -9},
+		{"AAAAABBBB ", false, 6, 1, -9, -9},
+	}
+
+	for idx, tt := range tts {
+		for i, ch := range tt.choices {
+			q.ByName(m.RowName(i)).Response = strings.TrimSpace(string(ch))
+		}
+		res := m.Result(q)
+		if res.Complete != tt.complete || res.SwitchRow != tt.switch1 || res.Switches != tt.switches {
+			t.Errorf("idx%v: got %+v", idx, res)
+		}
+		for _, pair := range [][2]float64{{res.CRRALow, tt.lo}, {res.CRRAHigh, tt.hi}} {
+			got, want := pair[0], pair[1]
+			if math.IsNaN(got) {
+				got = -9
+			}
+			if math.Abs(got-want) > 0.01 {
+				t.Errorf("idx%v: crra bound %.3f - want %.2f", idx, got, want)
+			}
+		}
+	}
+
+	q.ByName(m.RowName(9)).Response = "A"
+	err := validators["mplSingleSwitch"](q, q.ByName(m.RowName(0)))
+	if err == nil {
+		t.Errorf("multiple switching should be rejected")
+	}
+}
@@ -221,6 +221,10 @@ func (q *QuestionnaireT) Validate() error {
 					}
 				}
 
+				if inp.Prefill != "" && inp.Prefill != PrefillValue && inp.Prefill != PrefillHint {
+					return fmt.Errorf("%v - prefill must be '%v' or '%v'", s, PrefillValue, PrefillHint)
+				}
+
 				if !inp.IsLabelOnly() && !inp.IsHidden() {
 					if inp.ColSpanControl == 0 {
 						return fmt.Errorf("%v has no ColSpanControl", s)
@@ -322,6 +326,58 @@ func (q *QuestionnaireT) Validate() error {
 			return fmt.Errorf(s)
 		}
 	}
+
+	quotaNames := map[string]bool{}
+	for _, qt := range q.Quotas {
+		if qt.Name == "" || quotaNames[qt.Name] {
+			return fmt.Errorf("quota name '%v' empty or not unique", qt.Name)
+		}
+		quotaNames[qt.Name] = true
+		if qt.Page < 0 || qt.Page > len(q.Pages)-1 {
+			return fmt.Errorf("quota %v - page %v out of range", qt.Name, qt.Page)
+		}
+		if qt.ClosingPage < 0 || qt.ClosingPage > len(q.Pages)-1 {
+			return fmt.Errorf("quota %v - closing page %v out of range", qt.Name, qt.ClosingPage)
+		}
+		for name := range qt.Responses {
+			if names[name] == 0 && namesRadio[name] == 0 {
+				return fmt.Errorf("quota %v - condition on unknown input %v", qt.Name, name)
+			}
+		}
+	}
+
+	if err := q.validateEndStates(); err != nil {
+		return err
+	}
+
+	if err := q.validateChecks(); err != nil {
+		return err
+	}
+
+	if err := q.validateMPLs(); err != nil {
+		return err
+	}
+
+	if err := q.validateConjoints(); err != nil {
+		return err
+	}
+
+	if err := q.validateVignettes(); err != nil {
+		return err
+	}
+
+	if err := q.validatePayoff(); err != nil {
+		return err
+	}
+
+	if err := q.validateTimeLimits(); err != nil {
+		return err
+	}
+
+	if err := q.validateConsent(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -191,6 +191,17 @@ func (s SurveyT) WaveID() string {
 	return t.Format("2006-01")
 }
 
+// PreviousWaveID returns the wave ID of the preceding wave;
+// default is the previous month;
+// survey param "previous_wave_id" overrides - i.e. for quarterly surveys
+func (s SurveyT) PreviousWaveID() string {
+	if pw, err := s.Param("previous_wave_id"); err == nil && pw != "" {
+		return pw
+	}
+	t := time.Date(s.Year, s.Month-1, 1, 0, 0, 0, 0, cfg.Get().Loc)
+	return t.Format("2006-01")
+}
+
 // WaveIDPretty is empty, if we dont have proper year, otherwise like WaveID()
 func (s SurveyT) WaveIDPretty() string {
 	if s.Year == 0 {
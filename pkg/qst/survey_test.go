@@ -150,3 +150,25 @@ func Test_surveyT_MonthOfQuarter(t *testing.T) {
 		})
 	}
 }
+
+func Test_surveyT_PreviousWaveID(t *testing.T) {
+
+	cfg.LoadFakeConfigForTests()
+
+	tests := []struct {
+		name string
+		s    SurveyT
+		want string
+	}{
+		{"t1", SurveyT{Year: 2021, Month: 5}, "2021-04"},
+		{"t2", SurveyT{Year: 2021, Month: 1}, "2020-12"},
+		{"t3", SurveyT{Year: 2021, Month: 4, Params: []ParamT{{Name: "previous_wave_id", Val: "2021-01"}}}, "2021-01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.s.PreviousWaveID(); got != tt.want {
+				t.Errorf("surveyT.PreviousWaveID() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package qst
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/zew/go-questionnaire/pkg/cfg"
+)
+
+// actions of pageT.TimeoutAction - apart from end states
+const (
+	TimeoutSubmit = "submit" // default - the page is submitted automatically; responses are not validated
+	TimeoutLock   = "lock"   // inputs are disabled; the participant continues manually
+)
+
+// timeLimitGrace allows for the latency of the automatic submit;
+// responses arriving later are discarded
+const timeLimitGrace = 3 * time.Second
+
+// hasTimeLimit is true for pages with minimum or maximum duration
+func (p *pageT) hasTimeLimit() bool {
+	return p.MinSeconds > 0 || p.MaxSeconds > 0
+}
+
+// pageClock returns the time since page pageIdx was first entered;
+// reloading or revisiting the page does not restart the clock;
+// false for pages without time limits or not yet entered
+func (q *QuestionnaireT) pageClock(pageIdx int, now time.Time) (time.Duration, bool) {
+	if pageIdx < 0 || pageIdx > len(q.Pages)-1 {
+		return 0, false
+	}
+	pg := q.Pages[pageIdx]
+	if !pg.hasTimeLimit() || pg.Paradata == nil || pg.Paradata.FirstEntry.IsZero() {
+		return 0, false
+	}
+	return now.Sub(pg.Paradata.FirstEntry), true
+}
+
+// TimeLimitExceeded is true, if MaxSeconds of page pageIdx have passed - plus grace;
+// responses submitted from this page are to be discarded
+func (q *QuestionnaireT) TimeLimitExceeded(pageIdx int, now time.Time) bool {
+	elapsed, ok := q.pageClock(pageIdx, now)
+	if !ok || q.Pages[pageIdx].MaxSeconds == 0 {
+		return false
+	}
+	return elapsed > time.Duration(q.Pages[pageIdx].MaxSeconds)*time.Second+timeLimitGrace
+}
+
+// ApplyTimeLimits is called after validating page pageIdx with validationErr;
+// forward submits before MinSeconds are rejected;
+// after MaxSeconds validation errors are dropped - and TimeoutAction is taken;
+// the elapsed time of the first accepted submit is recorded in the paradata
+func (q *QuestionnaireT) ApplyTimeLimits(pageIdx int, now time.Time, forward bool, validationErr error) error {
+
+	elapsed, ok := q.pageClock(pageIdx, now)
+	if !ok {
+		return validationErr
+	}
+	pg := q.Pages[pageIdx]
+	pd := pg.Paradata
+	secs := int(elapsed.Round(time.Second).Seconds())
+
+	if pg.MaxSeconds > 0 && elapsed >= time.Duration(pg.MaxSeconds)*time.Second {
+		if !pd.TimedOut {
+			pd.TimedOut = true
+			log.Printf("user %v - page %v timed out after %v secs - %v", q.UserID, pageIdx, secs, pg.TimeoutAction)
+		}
+		if pd.Elapsed == 0 {
+			pd.Elapsed = secs
+		}
+		for _, gr := range pg.Groups {
+			for _, inp := range gr.Inputs {
+				inp.ErrMsg = ""
+			}
+		}
+		q.HasErrors = false
+		if _, ok := EndStatus[pg.TimeoutAction]; ok {
+			q.End(pg.TimeoutAction, now)
+		}
+		return nil
+	}
+
+	if forward && pg.MinSeconds > 0 && elapsed < time.Duration(pg.MinSeconds)*time.Second {
+		q.HasErrors = true
+		return fmt.Errorf(cfg.Get().Mp["page_time_min"].Tr(q.LangCode), pg.MinSeconds-secs)
+	}
+
+	if forward && validationErr == nil && pd.Elapsed == 0 {
+		pd.Elapsed = secs
+	}
+	return validationErr
+}
+
+// timeLimitHTML renders the countdown of page pageIdx;
+// the next buttons are enabled after MinSeconds;
+// after MaxSeconds, the page is submitted or locked
+func (q *QuestionnaireT) timeLimitHTML(pageIdx int, now time.Time) string {
+
+	pg := q.Pages[pageIdx]
+	if !pg.hasTimeLimit() {
+		return ""
+	}
+	elapsed, ok := q.pageClock(pageIdx, now)
+	if !ok {
+		elapsed = 0 // not yet entered - i.e. preview
+	}
+	minRemaining := pg.MinSeconds - int(elapsed.Seconds())
+	if minRemaining < 0 {
+		minRemaining = 0
+	}
+	maxRemaining := -1
+	if pg.MaxSeconds > 0 {
+		maxRemaining = pg.MaxSeconds - int(elapsed.Seconds())
+		if maxRemaining < 0 {
+			maxRemaining = 0
+		}
+	}
+	action := pg.TimeoutAction
+	if action != TimeoutLock {
+		action = TimeoutSubmit // end states are taken by the server upon submit
+	}
+
+	lc := q.LangCode
+	w := &strings.Builder{}
+
+	if q.HasErrors && minRemaining > 0 {
+		fmt.Fprintf(w, "<p class='error' >%v</p>\n", fmt.Sprintf(cfg.Get().Mp["page_time_min"].Tr(lc), minRemaining))
+	}
+	if maxRemaining > -1 {
+		fmt.Fprintf(w, "<p class='page-timer' id='page-timer' >%v <span id='page-timer-secs'>%v</span></p>\n",
+			cfg.Get().Mp["page_time_remaining"].Tr(lc), maxRemaining)
+		fmt.Fprintf(w, "<p class='page-timer' id='page-timer-over' style='display: none' >%v</p>\n",
+			cfg.Get().Mp["page_time_over"].Tr(lc))
+	}
+
+	s := `
+	<script>
+	document.addEventListener("DOMContentLoaded", function() {
+		var minRemaining = %v;
+		var maxRemaining = %v;
+		var action = "%v";
+		var frm = document.forms.frmMain;
+		var nexts = document.querySelectorAll("button[name='submitBtn'][value='next']");
+
+		function setNext(disabled) {
+			for (var i = 0; i < nexts.length; i++) {
+				nexts[i].disabled = disabled;
+			}
+		}
+		function lock() {
+			var els = frm.querySelectorAll("input, select, textarea");
+			for (var i = 0; i < els.length; i++) {
+				if (els[i].type !== "hidden") {
+					els[i].disabled = true;
+				}
+			}
+			setNext(false);
+			var tm = document.getElementById("page-timer");
+			if (tm) { tm.style.display = "none"; }
+			var ov = document.getElementById("page-timer-over");
+			if (ov) { ov.style.display = ""; }
+		}
+		function submit() {
+			var hd = document.createElement("input");
+			hd.type = "hidden";
+			hd.name = "submitBtn";
+			hd.value = "next";
+			frm.appendChild(hd);
+			frm.submit(); // bypasses client side validation
+		}
+
+		if (minRemaining > 0) {
+			setNext(true);
+			window.setTimeout(function() { setNext(false); }, 1000 * minRemaining);
+		}
+		if (maxRemaining < 0) {
+			return;
+		}
+		if (maxRemaining === 0) {
+			lock(); // revisiting a timed out page
+			return;
+		}
+		var secs = document.getElementById("page-timer-secs");
+		var started = Date.now();
+		var iv = window.setInterval(function() {
+			var left = maxRemaining - Math.floor((Date.now() - started) / 1000);
+			if (left > 0) {
+				secs.textContent = left;
+				return;
+			}
+			window.clearInterval(iv);
+			if (action === "submit") {
+				submit(); // disabled inputs would not be submitted
+			} else {
+				lock();
+			}
+		}, 250);
+	});
+	</script>
+	`
+	fmt.Fprintf(w, s, minRemaining, maxRemaining, action)
+	return w.String()
+}
+
+// validateTimeLimits is part of Validate()
+func (q *QuestionnaireT) validateTimeLimits() error {
+	for i, pg := range q.Pages {
+		if pg.MinSeconds < 0 || pg.MaxSeconds < 0 {
+			return fmt.Errorf("page %v - negative time limit", i)
+		}
+		if pg.MaxSeconds > 0 && pg.MinSeconds > pg.MaxSeconds {
+			return fmt.Errorf("page %v - min seconds %v exceed max seconds %v", i, pg.MinSeconds, pg.MaxSeconds)
+		}
+		if pg.TimeoutAction == "" || pg.TimeoutAction == TimeoutSubmit || pg.TimeoutAction == TimeoutLock {
+			continue
+		}
+		if _, ok := EndStatus[pg.TimeoutAction]; !ok {
+			return fmt.Errorf("page %v - timeout action %q neither %q, %q nor an end state", i, pg.TimeoutAction, TimeoutSubmit, TimeoutLock)
+		}
+		if pg.MaxSeconds == 0 {
+			return fmt.Errorf("page %v - timeout action without max seconds", i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package qst
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/zew/go-questionnaire/pkg/cfg"
+)
+
+func TestQuestionnaireT_ApplyTimeLimits(t *testing.T) {
+
+	cfg.LoadFakeConfigForTests()
+
+	q := newTestQ(3)
+	q.Pages[0].MinSeconds = 10
+	q.Pages[0].MaxSeconds = 60
+	q.Pages[1].MaxSeconds = 30
+	q.Pages[1].TimeoutAction = EndTimedOut
+	q.Pages[2].EndState = EndTimedOut
+	if err := q.validateTimeLimits(); err != nil {
+		t.Fatal(err)
+	}
+
+	t0 := time.Date(2022, 4, 1, 12, 0, 0, 0, time.UTC)
+	sec := func(s int) time.Time { return t0.Add(time.Duration(s) * time.Second) }
+	errValidation := fmt.Errorf("validation")
+
+	q.ParadataEnter(0, 0, sec(0), false)
+	if err := q.ApplyTimeLimits(0, sec(5), true, nil); err == nil {
+		t.Errorf("early submit should be rejected")
+	}
+	if err := q.ApplyTimeLimits(0, sec(5), false, nil); err != nil {
+		t.Errorf("going back early should be allowed: %v", err)
+	}
+	if err := q.ApplyTimeLimits(0, sec(12), true, errValidation); err != errValidation {
+		t.Errorf("validation error should be kept: %v", err)
+	}
+	if q.Pages[0].Paradata.Elapsed != 0 {
+		t.Errorf("rejected submit should not be recorded")
+	}
+	if err := q.ApplyTimeLimits(0, sec(20), true, nil); err != nil || q.Pages[0].Paradata.Elapsed != 20 {
+		t.Errorf("accepted submit: %v - elapsed %v", err, q.Pages[0].Paradata.Elapsed)
+	}
+	if q.TimeLimitExceeded(0, sec(63)) || !q.TimeLimitExceeded(0, sec(64)) {
+		t.Errorf("time limit should be exceeded after max seconds plus grace")
+	}
+
+	q.ParadataEnter(1, 0, sec(100), false)
+	if err := q.ApplyTimeLimits(1, sec(131), true, errValidation); err != nil {
+		t.Errorf("validation errors after timeout should be dropped: %v", err)
+	}
+	pd := q.Pages[1].Paradata
+	if !pd.TimedOut || pd.Elapsed != 31 || q.EndState != EndTimedOut || q.CurrPage != 2 {
+		t.Errorf("timeout: %+v - end state %v - page %v", pd, q.EndState, q.CurrPage)
+	}
+
+	q.Pages[1].MinSeconds = 40
+	if err := q.validateTimeLimits(); err == nil {
+		t.Errorf("min seconds exceeding max seconds should be invalid")
+	}
+}
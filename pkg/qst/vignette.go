@@ -0,0 +1,155 @@
+package qst
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/zew/go-questionnaire/pkg/lgn/shuffler"
+	"github.com/zew/go-questionnaire/pkg/trl"
+)
+
+// VignetteDimT is a randomized dimension of a vignette - i.e. firm size
+type VignetteDimT struct {
+	Name   string  `json:"name"` // placeholder {{name}} in the vignette text
+	Levels []trl.S `json:"levels"`
+}
+
+// VignetteT is the text of a factorial survey experiment;
+// the combination of levels is drawn per participant - reproducible via shuffler;
+// each block of consecutive user IDs sees all combinations in random order;
+// the drawn levels are stored in hidden inputs Name_dimension - one based;
+// thus they are exported as separate columns
+type VignetteT struct {
+	Name       string         `json:"name"`
+	Text       trl.S          `json:"text"` // i.e. "A firm with {{size}} employees in {{sector}}..."
+	Dims       []VignetteDimT `json:"dims"`
+	Variations int            `json:"variations,omitempty"` // number of different orders of the combinations - see shuffler; default 100
+}
+
+// InputName returns the name of the hidden input for dimension dimIdx
+func (v VignetteT) InputName(dimIdx int) string {
+	return v.Name + "_" + v.Dims[dimIdx].Name
+}
+
+// Draw returns the one based level for each dimension
+func (v VignetteT) Draw(userID int) []int {
+
+	total := 1
+	for _, dim := range v.Dims {
+		total *= len(dim.Levels)
+	}
+	variations := v.Variations
+	if variations == 0 {
+		variations = 100
+	}
+
+	// combinations in random order - a different order for each block of users
+	block := userID / total
+	sh := shuffler.New(block, variations, total)
+	combo := sh.Slice(0)[(userID%total+total)%total]
+
+	// combination index to levels - mixed radix
+	lvls := make([]int, len(v.Dims))
+	for i, dim := range v.Dims {
+		lvls[i] = combo%len(dim.Levels) + 1
+		combo /= len(dim.Levels)
+	}
+	return lvls
+}
+
+// Label fills the placeholders of the text with the levels - in all languages
+func (v VignetteT) Label(lvls []int) trl.S {
+	ret := trl.S{}
+	for lc, txt := range v.Text {
+		for i, dim := range v.Dims {
+			lbl := ""
+			if i < len(lvls) && lvls[i] > 0 && lvls[i] <= len(dim.Levels) {
+				lbl = dim.Levels[lvls[i]-1].TrSilent(lc)
+			}
+			txt = strings.ReplaceAll(txt, "{{"+dim.Name+"}}", lbl)
+		}
+		ret[lc] = txt
+	}
+	return ret
+}
+
+// VignetteByName returns the vignette; or false
+func (q *QuestionnaireT) VignetteByName(name string) (VignetteT, bool) {
+	for _, v := range q.Vignettes {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return VignetteT{}, false
+}
+
+// AddVignette adds the vignette text and the hidden inputs
+// for the drawn levels to page p;
+// and registers v with the questionnaire
+func (q *QuestionnaireT) AddVignette(p *pageT, v VignetteT) *groupT {
+
+	q.Vignettes = append(q.Vignettes, v)
+
+	gr := p.AddGroup()
+	gr.Cols = 1
+
+	inp := gr.AddInput()
+	inp.Type = "dyn-textblock"
+	inp.DynamicFunc = "Vignette"
+	inp.DynamicFuncParamset = v.Name
+	inp.ColSpan = 1
+
+	for i := range v.Dims {
+		hd := gr.AddInput()
+		hd.Type = "hidden"
+		hd.Name = v.InputName(i)
+	}
+	return gr
+}
+
+// Vignette renders the vignette text;
+// the drawn levels are written into the hidden inputs;
+// paramSet is the name of the vignette
+func Vignette(q *QuestionnaireT, inp *inputT, paramSet string) (string, error) {
+
+	v, ok := q.VignetteByName(strings.TrimSpace(paramSet))
+	if !ok {
+		return "", fmt.Errorf("vignette: no vignette %q", paramSet)
+	}
+
+	lvls := v.Draw(q.UserIDInt())
+	for i, lvl := range lvls {
+		hd := q.ByName(v.InputName(i))
+		if hd == nil {
+			return "", fmt.Errorf("vignette %v: no input %v", v.Name, v.InputName(i))
+		}
+		hd.Response = strconv.Itoa(lvl) // overwrites tampered values
+	}
+	return v.Label(lvls).TrSilent(q.LangCode), nil
+}
+
+// validateVignettes is part of Validate()
+func (q *QuestionnaireT) validateVignettes() error {
+	names := map[string]bool{}
+	for _, v := range q.Vignettes {
+		if v.Name == "" || names[v.Name] {
+			return fmt.Errorf("vignette name '%v' empty or not unique", v.Name)
+		}
+		names[v.Name] = true
+		for i, dim := range v.Dims {
+			if len(dim.Levels) < 2 {
+				return fmt.Errorf("vignette %v - dimension %v needs two levels", v.Name, dim.Name)
+			}
+			for lc, txt := range v.Text {
+				if !strings.Contains(txt, "{{"+dim.Name+"}}") {
+					return fmt.Errorf("vignette %v - text %v lacks placeholder {{%v}}", v.Name, lc, dim.Name)
+				}
+			}
+			if q.ByName(v.InputName(i)) == nil {
+				return fmt.Errorf("vignette %v - input %v missing", v.Name, v.InputName(i))
+			}
+		}
+	}
+	return nil
+}
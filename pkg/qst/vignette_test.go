@@ -0,0 +1,57 @@
+package qst
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/zew/go-questionnaire/pkg/trl"
+)
+
+func TestVignetteT(t *testing.T) {
+
+	v := VignetteT{
+		Name: "vig",
+		Text: trl.S{
+			"de": "Eine Firma mit {{size}} Beschäftigten im Sektor {{sector}}.",
+			"en": "A firm with {{size}} employees in the {{sector}} sector.",
+		},
+		Dims: []VignetteDimT{
+			{Name: "size", Levels: []trl.S{{"de": "10", "en": "10"}, {"de": "500", "en": "500"}}},
+			{Name: "sector", Levels: []trl.S{{"de": "Bau", "en": "construction"}, {"de": "Handel", "en": "retail"}, {"de": "Industrie", "en": "manufacturing"}}},
+		},
+	}
+
+	q := &QuestionnaireT{UserID: "1003", LangCode: "en"}
+	q.AddVignette(q.AddPage(), v)
+	if err := q.validateVignettes(); err != nil {
+		t.Fatal(err)
+	}
+
+	txt, err := Vignette(q, nil, "vig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	size, _ := strconv.Atoi(q.ByName("vig_size").Response)
+	sector, _ := strconv.Atoi(q.ByName("vig_sector").Response)
+	if size < 1 || size > 2 || sector < 1 || sector > 3 {
+		t.Fatalf("levels out of range: %v %v", size, sector)
+	}
+	want := v.Label([]int{size, sector})
+	if txt != want["en"] || strings.Contains(want["de"], "{{") {
+		t.Errorf("got %q - want %q", txt, want["en"])
+	}
+	if fmt.Sprint(v.Draw(1003)) != fmt.Sprint([]int{size, sector}) {
+		t.Errorf("levels not reproducible")
+	}
+
+	// over many participants, all levels occur
+	seen := map[string]bool{}
+	for userID := 1; userID < 200; userID++ {
+		seen[fmt.Sprint(v.Draw(userID))] = true
+	}
+	if len(seen) != 6 {
+		t.Errorf("want all 6 combinations - got %v", len(seen))
+	}
+}
@@ -0,0 +1,301 @@
+package stats
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zew/go-questionnaire/pkg/qst"
+)
+
+// MinCellDefault is the smallest count shown;
+// smaller counts could identify individual participants
+const MinCellDefault = 5
+
+// FilterT restricts the questionnaires included in the statistics
+type FilterT struct {
+	Attrs    map[string]string // all must match q.Attrs
+	Finished string            // "1" - finished only, "0" - unfinished only, "" - all
+}
+
+// ParseAttrFilter parses "country:de,sector:bank"
+func ParseAttrFilter(s string) map[string]string {
+	ret := map[string]string{}
+	for _, kv := range strings.Split(s, ",") {
+		kv = strings.TrimSpace(kv)
+		if kv == "" {
+			continue
+		}
+		parts := strings.SplitN(kv, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		ret[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return ret
+}
+
+// Match is true, if q passes the filter
+func (f FilterT) Match(q *qst.QuestionnaireT) bool {
+	if f.Finished == "1" && q.ClosingTime.IsZero() {
+		return false
+	}
+	if f.Finished == "0" && !q.ClosingTime.IsZero() {
+		return false
+	}
+	for k, v := range f.Attrs {
+		if q.Attrs[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// OptionT is one row of a frequency table
+type OptionT struct {
+	Value      string
+	Label      string
+	Count      int
+	Suppressed bool // Count below MinCell - must not be shown
+}
+
+// NumericT summarizes the responses of a number input;
+// empty if N is below MinCell
+type NumericT struct {
+	N          int
+	Mean       float64
+	Median     float64
+	Q10, Q25   float64
+	Q75, Q90   float64
+	Suppressed bool
+}
+
+// QuestionT collects the responses to one input
+type QuestionT struct {
+	Name  string
+	Type  string // radio, dropdown or number
+	Label string
+	N     int // number of non-empty responses
+
+	NSuppressed bool // N would reveal a suppressed count - must not be shown
+
+	Options []OptionT // radio and dropdown - computed by Finalize()
+	Numeric NumericT  // number - computed by Finalize()
+
+	order   []string          // option values in template order
+	labels  map[string]string // option labels
+	counts  map[string]int
+	numbers []float64
+}
+
+// FrequenciesT contains per-input statistics of a wave;
+// questions appear in the order of the first questionnaire;
+// questionnaires are added one by one - see tf.RetrieveEach()
+type FrequenciesT struct {
+	Filter   FilterT
+	MinCell  int
+	LangCode string // for labels
+
+	N         int // questionnaires passing the filter
+	Questions []*QuestionT
+
+	byName map[string]*QuestionT
+}
+
+// NewFrequencies returns an empty FrequenciesT;
+// minCell cannot be set below MinCellDefault
+func NewFrequencies(filter FilterT, minCell int, langCode string) *FrequenciesT {
+	if minCell < MinCellDefault {
+		minCell = MinCellDefault
+	}
+	return &FrequenciesT{
+		Filter:   filter,
+		MinCell:  minCell,
+		LangCode: langCode,
+		byName:   map[string]*QuestionT{},
+	}
+}
+
+// question returns the QuestionT for inp - creating it on first encounter
+func (fr *FrequenciesT) question(q *qst.QuestionnaireT, typ, name, label string) *QuestionT {
+	qu, ok := fr.byName[name]
+	if !ok {
+		qu = &QuestionT{
+			Name:   name,
+			Type:   typ,
+			Label:  q.LabelCleanse(label),
+			labels: map[string]string{},
+			counts: map[string]int{},
+		}
+		fr.byName[name] = qu
+		fr.Questions = append(fr.Questions, qu)
+	}
+	return qu
+}
+
+// option registers an option value with its label - once
+func (qu *QuestionT) option(val, lbl string) {
+	if _, ok := qu.labels[val]; ok {
+		return
+	}
+	qu.order = append(qu.order, val)
+	qu.labels[val] = lbl
+}
+
+// Add accumulates q, if it passes the filter;
+// signature fits tf.RetrieveEach()
+func (fr *FrequenciesT) Add(q *qst.QuestionnaireT) error {
+
+	if !fr.Filter.Match(q) {
+		return nil
+	}
+	fr.N++
+
+	seen := map[string]bool{} // radios share their name
+	for _, pg := range q.Pages {
+		for _, gr := range pg.Groups {
+			for _, inp := range gr.Inputs {
+				switch inp.Type {
+				case "radio":
+					qu := fr.question(q, inp.Type, inp.Name, "")
+					qu.option(inp.ValueRadio, q.LabelCleanse(inp.Label.TrSilent(fr.LangCode)))
+					if inp.Response == "" || seen[inp.Name] {
+						continue
+					}
+					seen[inp.Name] = true
+					qu.N++
+					qu.counts[inp.Response]++
+				case "dropdown":
+					qu := fr.question(q, inp.Type, inp.Name, inp.Label.TrSilent(fr.LangCode))
+					if inp.DD != nil {
+						for _, opt := range inp.DD.Options {
+							if opt.Key == "" {
+								continue // 'please choose'
+							}
+							qu.option(opt.Key, opt.Val.TrSilent(fr.LangCode))
+						}
+					}
+					if inp.Response == "" {
+						continue
+					}
+					qu.N++
+					qu.counts[inp.Response]++
+				case "number":
+					qu := fr.question(q, inp.Type, inp.Name, inp.Label.TrSilent(fr.LangCode))
+					if inp.Response == "" {
+						continue
+					}
+					fl, err := strconv.ParseFloat(qst.DelocalizeNumber(inp.Response), 64)
+					if err != nil {
+						continue
+					}
+					qu.N++
+					qu.numbers = append(qu.numbers, fl)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Finalize computes options and numeric summaries;
+// call after the last Add()
+func (fr *FrequenciesT) Finalize() {
+
+	for _, qu := range fr.Questions {
+
+		if qu.Type == "number" {
+			qu.Numeric = summarize(qu.numbers, fr.MinCell)
+			continue
+		}
+
+		qu.Options = qu.Options[:0]
+		for _, val := range qu.order {
+			qu.Options = append(qu.Options, OptionT{Value: val, Label: qu.labels[val], Count: qu.counts[val]})
+		}
+		// responses without option - i.e. from an older version
+		var extra []string
+		for val := range qu.counts {
+			if _, ok := qu.labels[val]; !ok {
+				extra = append(extra, val)
+			}
+		}
+		sort.Strings(extra)
+		for _, val := range extra {
+			qu.Options = append(qu.Options, OptionT{Value: val, Count: qu.counts[val]})
+		}
+
+		qu.NSuppressed = !suppress(qu.Options, fr.MinCell)
+	}
+
+}
+
+// suppress marks counts below minCell;
+// a single suppressed cell could be recovered from the total;
+// then the next smallest count is suppressed as well;
+// returns false, if there is no such count - then the total must be suppressed
+func suppress(opts []OptionT, minCell int) bool {
+	cnt := 0
+	for i := range opts {
+		if opts[i].Count > 0 && opts[i].Count < minCell {
+			opts[i].Suppressed = true
+			cnt++
+		}
+	}
+	if cnt != 1 {
+		return true
+	}
+	next := -1
+	for i := range opts {
+		if opts[i].Suppressed || opts[i].Count == 0 {
+			continue
+		}
+		if next < 0 || opts[i].Count < opts[next].Count {
+			next = i
+		}
+	}
+	if next < 0 {
+		return false
+	}
+	opts[next].Suppressed = true
+	return true
+}
+
+// summarize computes mean and quantiles;
+// minimum and maximum are deliberately omitted - they are individual answers
+func summarize(nums []float64, minCell int) NumericT {
+	ret := NumericT{N: len(nums)}
+	if len(nums) < minCell {
+		ret.Suppressed = true
+		return ret
+	}
+	sorted := append([]float64{}, nums...)
+	sort.Float64s(sorted)
+	sum := 0.0
+	for _, fl := range sorted {
+		sum += fl
+	}
+	ret.Mean = sum / float64(len(sorted))
+	ret.Median = Quantile(sorted, 0.5)
+	ret.Q10 = Quantile(sorted, 0.1)
+	ret.Q25 = Quantile(sorted, 0.25)
+	ret.Q75 = Quantile(sorted, 0.75)
+	ret.Q90 = Quantile(sorted, 0.9)
+	return ret
+}
+
+// Quantile of sorted values - linear interpolation between closest ranks
+func Quantile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return math.NaN()
+	}
+	pos := p * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	if lo == hi {
+		return sorted[lo]
+	}
+	return sorted[lo] + (pos-float64(lo))*(sorted[hi]-sorted[lo])
+}
@@ -0,0 +1,51 @@
+package stats
+
+import (
+	"math"
+	"testing"
+)
+
+func TestQuantile(t *testing.T) {
+	sorted := []float64{1, 2, 3, 4}
+	tests := []struct {
+		p    float64
+		want float64
+	}{
+		{0, 1},
+		{0.5, 2.5},
+		{0.25, 1.75},
+		{1, 4},
+	}
+	for _, tt := range tests {
+		if got := Quantile(sorted, tt.p); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Quantile(%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestSuppress(t *testing.T) {
+	tests := []struct {
+		counts []int
+		want   []bool
+		wantN  bool // total may be shown
+	}{
+		{[]int{10, 7, 0}, []bool{false, false, false}, true},
+		{[]int{10, 7, 2}, []bool{false, true, true}, true}, // secondary suppression
+		{[]int{10, 3, 2}, []bool{false, true, true}, true},
+		{[]int{3, 0}, []bool{true, false}, false}, // N would reveal the count
+	}
+	for i, tt := range tests {
+		opts := make([]OptionT, len(tt.counts))
+		for j, c := range tt.counts {
+			opts[j].Count = c
+		}
+		if gotN := suppress(opts, MinCellDefault); gotN != tt.wantN {
+			t.Errorf("test %v: total shown %v, want %v", i, gotN, tt.wantN)
+		}
+		for j := range opts {
+			if opts[j].Suppressed != tt.want[j] {
+				t.Errorf("test %v: option %v suppressed %v, want %v", i, j, opts[j].Suppressed, tt.want[j])
+			}
+		}
+	}
+}
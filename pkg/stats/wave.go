@@ -0,0 +1,224 @@
+// Package stats computes aggregate statistics
+// over the response files of a survey wave;
+// questionnaires are added one by one - see tf.RetrieveEach() -
+// so that memory use does not grow with the number of participants.
+package stats
+
+import (
+	"sort"
+	"time"
+
+	"github.com/zew/go-questionnaire/pkg/detect"
+	"github.com/zew/go-questionnaire/pkg/qst"
+)
+
+// DayT contains the counts of one day
+type DayT struct {
+	Day                 string // yyyy-mm-dd
+	Started, Finished   int
+	CumStarted, CumFini int
+}
+
+// CountT is a name with a count - for sorted display
+type CountT struct {
+	Name  string
+	Count int
+}
+
+// WaveT summarizes completion and dropout of a wave
+type WaveT struct {
+	SurveyID string
+	WaveID   string
+	Computed time.Time
+
+	Started  int // number of response files
+	Finished int // ClosingTime set - and not screened out
+
+	EndStates map[string]int // screened out, over quota... - see qst.EndStatus
+
+	Days []DayT // started and finished per day; computed by Finalize()
+
+	DropoutByPage map[int]int    // unfinished questionnaires by current page - zero based
+	Langs         map[string]int // by lang code
+	Mobile        int
+	Desktop       int
+	Unknown       int            // no paradata and no user agent
+	ErrorsByInput map[string]int // failed validation submits per input - from paradata
+
+	MedianCompletion time.Duration // computed by Finalize()
+
+	startedByDay  map[string]int
+	finishedByDay map[string]int
+	durations     []time.Duration
+}
+
+// NewWave returns an empty WaveT
+func NewWave(surveyID, waveID string) *WaveT {
+	return &WaveT{
+		SurveyID:      surveyID,
+		WaveID:        waveID,
+		DropoutByPage: map[int]int{},
+		Langs:         map[string]int{},
+		EndStates:     map[string]int{},
+		ErrorsByInput: map[string]int{},
+		startedByDay:  map[string]int{},
+		finishedByDay: map[string]int{},
+	}
+}
+
+// StartTime is the earliest page entry or page finish time;
+// zero, if the participant never submitted anything
+func StartTime(q *qst.QuestionnaireT) time.Time {
+	start := time.Time{}
+	earlier := func(t time.Time) {
+		if t.IsZero() {
+			return
+		}
+		if start.IsZero() || t.Before(start) {
+			start = t
+		}
+	}
+	for _, pg := range q.Pages {
+		if pg.Paradata != nil {
+			earlier(pg.Paradata.FirstEntry)
+		}
+		earlier(pg.Finished)
+	}
+	return start
+}
+
+// Device returns "mobile", "desktop" or "" -
+// from paradata or from the user agent
+func Device(q *qst.QuestionnaireT) string {
+	for _, pg := range q.Pages {
+		if pg.Paradata != nil && pg.Paradata.Device == "mobile" {
+			return "mobile"
+		}
+	}
+	for _, pg := range q.Pages {
+		if pg.Paradata != nil && pg.Paradata.Device != "" {
+			return pg.Paradata.Device
+		}
+	}
+	if q.UserAgent == "" {
+		return ""
+	}
+	if detect.IsMobileUA(q.UserAgent) {
+		return "mobile"
+	}
+	return "desktop"
+}
+
+// Add accumulates q;
+// signature fits tf.RetrieveEach()
+func (w *WaveT) Add(q *qst.QuestionnaireT) error {
+
+	w.Started++
+
+	start := StartTime(q)
+	if !start.IsZero() {
+		w.startedByDay[start.Format("2006-01-02")]++
+	}
+
+	if q.EndState != "" && q.EndState != qst.EndComplete {
+		w.EndStates[q.EndState]++
+	} else if q.ClosingTime.IsZero() {
+		w.DropoutByPage[q.CurrPage]++
+	} else {
+		w.Finished++
+		w.finishedByDay[q.ClosingTime.Format("2006-01-02")]++
+		if !start.IsZero() && q.ClosingTime.After(start) {
+			w.durations = append(w.durations, q.ClosingTime.Sub(start))
+		}
+	}
+
+	w.Langs[q.LangCode]++
+
+	switch Device(q) {
+	case "mobile":
+		w.Mobile++
+	case "desktop":
+		w.Desktop++
+	default:
+		w.Unknown++
+	}
+
+	for _, pg := range q.Pages {
+		if pg.Paradata == nil {
+			continue
+		}
+		for name, cnt := range pg.Paradata.InputErrors {
+			w.ErrorsByInput[name] += cnt
+		}
+	}
+
+	return nil
+}
+
+// Finalize computes days and median;
+// call after the last Add()
+func (w *WaveT) Finalize() {
+
+	days := map[string]bool{}
+	for d := range w.startedByDay {
+		days[d] = true
+	}
+	for d := range w.finishedByDay {
+		days[d] = true
+	}
+	keys := make([]string, 0, len(days))
+	for d := range days {
+		keys = append(keys, d)
+	}
+	sort.Strings(keys)
+
+	w.Days = make([]DayT, 0, len(keys))
+	cumS, cumF := 0, 0
+	for _, d := range keys {
+		cumS += w.startedByDay[d]
+		cumF += w.finishedByDay[d]
+		w.Days = append(w.Days, DayT{
+			Day:        d,
+			Started:    w.startedByDay[d],
+			Finished:   w.finishedByDay[d],
+			CumStarted: cumS,
+			CumFini:    cumF,
+		})
+	}
+
+	w.MedianCompletion = 0
+	if len(w.durations) > 0 {
+		sort.Slice(w.durations, func(i, j int) bool { return w.durations[i] < w.durations[j] })
+		mid := len(w.durations) / 2
+		if len(w.durations)%2 == 1 {
+			w.MedianCompletion = w.durations[mid]
+		} else {
+			w.MedianCompletion = (w.durations[mid-1] + w.durations[mid]) / 2
+		}
+	}
+
+	w.Computed = time.Now().Truncate(time.Second)
+}
+
+// Sorted returns the entries of mp by descending count
+func Sorted(mp map[string]int) []CountT {
+	ret := make([]CountT, 0, len(mp))
+	for k, v := range mp {
+		ret = append(ret, CountT{k, v})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Count != ret[j].Count {
+			return ret[i].Count > ret[j].Count
+		}
+		return ret[i].Name < ret[j].Name
+	})
+	return ret
+}
+
+// Share returns part of total in percent
+func Share(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(part) / float64(total)
+}
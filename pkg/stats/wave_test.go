@@ -0,0 +1,66 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zew/go-questionnaire/pkg/qst"
+)
+
+func TestWaveT(t *testing.T) {
+
+	t0 := time.Date(2022, 1, 10, 9, 0, 0, 0, time.UTC)
+
+	newQ := func(lang string, start time.Time, mins int, finished bool, currPage int) *qst.QuestionnaireT {
+		q := &qst.QuestionnaireT{LangCode: lang, CurrPage: currPage}
+		for i := 0; i < 3; i++ {
+			q.AddPage()
+		}
+		q.Pages[0].Paradata = &qst.ParadataT{
+			FirstEntry:  start,
+			Device:      "desktop",
+			InputErrors: map[string]int{"q1": 1},
+		}
+		if finished {
+			q.ClosingTime = start.Add(time.Duration(mins) * time.Minute)
+		}
+		return q
+	}
+
+	ws := NewWave("fmt", "2022-01")
+	qs := []*qst.QuestionnaireT{
+		newQ("de", t0, 10, true, 2),
+		newQ("de", t0.Add(24*time.Hour), 20, true, 2),
+		newQ("en", t0.Add(24*time.Hour), 40, true, 2),
+		newQ("de", t0.Add(48*time.Hour), 0, false, 1),
+	}
+	qs[3].Pages[0].Paradata.Device = "mobile"
+	for _, q := range qs {
+		if err := ws.Add(q); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ws.Finalize()
+
+	if ws.Started != 4 || ws.Finished != 3 {
+		t.Errorf("started/finished: got %v/%v", ws.Started, ws.Finished)
+	}
+	if ws.MedianCompletion != 20*time.Minute {
+		t.Errorf("median: got %v", ws.MedianCompletion)
+	}
+	if ws.DropoutByPage[1] != 1 || len(ws.DropoutByPage) != 1 {
+		t.Errorf("dropout: got %v", ws.DropoutByPage)
+	}
+	if ws.Langs["de"] != 3 || ws.Langs["en"] != 1 {
+		t.Errorf("langs: got %v", ws.Langs)
+	}
+	if ws.Mobile != 1 || ws.Desktop != 3 {
+		t.Errorf("devices: got %v mobile, %v desktop", ws.Mobile, ws.Desktop)
+	}
+	if ws.ErrorsByInput["q1"] != 4 {
+		t.Errorf("errors: got %v", ws.ErrorsByInput)
+	}
+	if len(ws.Days) != 3 || ws.Days[2].CumStarted != 4 || ws.Days[2].CumFini != 3 {
+		t.Errorf("days: got %+v", ws.Days)
+	}
+}
@@ -0,0 +1,48 @@
+package tf
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/zew/go-questionnaire/pkg/qst"
+)
+
+// checkNames returns the sorted names of all checks
+// with results in any questionnaire
+func checkNames(qs []*qst.QuestionnaireT) []string {
+	mp := map[string]bool{}
+	for _, q := range qs {
+		for name := range q.CheckResults {
+			mp[name] = true
+		}
+	}
+	names := make([]string, 0, len(mp))
+	for name := range mp {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// checkCols returns two columns per check - attempts and result
+func checkCols(names []string) []string {
+	cols := make([]string, 0, 2*len(names))
+	for _, name := range names {
+		cols = append(cols, "check_"+name+"_attempts", "check_"+name+"_result")
+	}
+	return cols
+}
+
+// checkVals returns the values for checkCols()
+func checkVals(q *qst.QuestionnaireT, names []string) []string {
+	vals := make([]string, 0, 2*len(names))
+	for _, name := range names {
+		res, ok := q.CheckResults[name]
+		if !ok {
+			vals = append(vals, "", "")
+			continue
+		}
+		vals = append(vals, fmt.Sprint(res.Attempts), res.Result)
+	}
+	return vals
+}
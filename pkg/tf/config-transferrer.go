@@ -19,6 +19,10 @@
 // then we have to append format=CSV to the usual URL parameters;
 // for instance
 // https://survey2.zew.de:443/transferrer-endpoint?fetch_all=1&survey_id=fmt&wave_id=2022-04&format=CSV
+// format=XLSX returns an Excel file with a data sheet and a labels sheet.
+// CSV params delimiter=tab, decimal=comma, bom=1, label_row=1, radio_labels=1
+// and label_lang=de - the language of question and radio labels - override RemoteConnConfigT.CSV.
+// format=LONG and format=JSONL are streamed - one row per input, or one line per participant.
 //
 // Consider to get rid of the standalone mode - since the download of JSON files
 // has become uninteresting
@@ -64,6 +68,8 @@ type RemoteConnConfigT struct {
 	DownloadDir string
 	MinUserID   int // constrain range of UserIDs being processed, exclude test user data entry
 	MaxUserID   int // see MinUserID
+
+	CSV CSVDialectT // delimiter, decimal separator, label row...
 }
 
 // Example1 returns a minimal configuration for usage as client
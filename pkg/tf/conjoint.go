@@ -0,0 +1,99 @@
+package tf
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	"github.com/zew/go-questionnaire/pkg/qst"
+)
+
+// ConjointWriterT writes the design matrix of conjoint experiments;
+// one row per participant, task and profile - ready for conditional logit;
+// attribute columns contain one based level indexes;
+// the columns are taken from the first questionnaire
+type ConjointWriterT struct {
+	csvWtr *csv.Writer
+	attrs  []string // union of attribute names
+	rows   int
+}
+
+// NewConjointWriter writes to w; the header is written with the first questionnaire
+func NewConjointWriter(w io.Writer) *ConjointWriterT {
+	cw := &ConjointWriterT{csvWtr: csv.NewWriter(w)}
+	cw.csvWtr.Comma = ';'
+	return cw
+}
+
+// Write appends the rows of all tasks of q, which were shown
+func (cw *ConjointWriterT) Write(q *qst.QuestionnaireT) error {
+
+	if cw.attrs == nil {
+		cw.attrs = []string{}
+		seen := map[string]bool{}
+		for _, c := range q.Conjoints {
+			for _, attr := range c.Attrs {
+				if !seen[attr.Name] {
+					seen[attr.Name] = true
+					cw.attrs = append(cw.attrs, attr.Name)
+				}
+			}
+		}
+		hdr := append([]string{"user_id", "conjoint", "task", "profile", "chosen"}, cw.attrs...)
+		if err := cw.csvWtr.Write(hdr); err != nil {
+			return fmt.Errorf("error writing header line to csv: %w", err)
+		}
+	}
+
+	for _, c := range q.Conjoints {
+		for t := 0; t < c.Tasks; t++ {
+			profiles, ok := q.ConjointShown[c.TaskName(t)]
+			if !ok {
+				continue
+			}
+			choice := ""
+			if inp := q.ByName(c.TaskName(t)); inp != nil {
+				choice = inp.Response
+			}
+			for p, lvls := range profiles {
+				chosen := ""
+				if choice != "" {
+					chosen = "0"
+					if choice == fmt.Sprint(p+1) {
+						chosen = "1"
+					}
+				}
+				byAttr := map[string]int{}
+				for a, attr := range c.Attrs {
+					if a < len(lvls) {
+						byAttr[attr.Name] = lvls[a]
+					}
+				}
+				rec := []string{q.UserID, c.Name, fmt.Sprint(t + 1), fmt.Sprint(p + 1), chosen}
+				for _, name := range cw.attrs {
+					val := ""
+					if lvl, ok := byAttr[name]; ok {
+						val = fmt.Sprint(lvl)
+					}
+					rec = append(rec, val)
+				}
+				if err := cw.csvWtr.Write(rec); err != nil {
+					return fmt.Errorf("error writing record to csv: %w", err)
+				}
+				cw.rows++
+			}
+		}
+	}
+	return nil
+}
+
+// Flush must be called after the last Write
+func (cw *ConjointWriterT) Flush() error {
+	cw.csvWtr.Flush()
+	return cw.csvWtr.Error()
+}
+
+// Rows returns the number of data rows written so far
+func (cw *ConjointWriterT) Rows() int {
+	return cw.rows
+}
@@ -0,0 +1,33 @@
+package tf
+
+import (
+	"fmt"
+
+	"github.com/zew/go-questionnaire/pkg/qst"
+)
+
+// hasConsent is true, if any questionnaire has a consent page
+func hasConsent(qs []*qst.QuestionnaireT) bool {
+	for _, q := range qs {
+		if q.Consent != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// consentCols are the columns of the consent record
+var consentCols = []string{"consent_markdown", "consent_hash", "consent_time"}
+
+// consentVals returns the values for consentCols;
+// consent_time is unix seconds - as closing_time
+func consentVals(q *qst.QuestionnaireT) []string {
+	if q.ConsentGiven == nil {
+		return []string{"", "", ""}
+	}
+	return []string{
+		q.ConsentGiven.Markdown,
+		q.ConsentGiven.Hash,
+		fmt.Sprint(q.ConsentGiven.Accepted.Unix()),
+	}
+}
@@ -0,0 +1,159 @@
+package tf
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/zew/go-questionnaire/pkg/qst"
+)
+
+// CSVDialectT configures the CSV export of ProcessQs;
+// the zero value yields semicolon separated codes with decimal points
+type CSVDialectT struct {
+	Delimiter        string `json:"delimiter,omitempty"`         // default ";" - use "\t" or "," for other applications
+	DecimalSeparator string `json:"decimal_separator,omitempty"` // default "." - use "," for German Excel
+	BOM              bool   `json:"bom,omitempty"`               // prepend UTF-8 byte order mark - makes Excel recognize UTF-8
+	LabelRow         bool   `json:"label_row,omitempty"`         // second header row containing the question texts
+	RadioLabels      bool   `json:"radio_labels,omitempty"`      // radio and dropdown values as label texts instead of codes
+	LabelLang        string `json:"label_lang,omitempty"`        // language of labels; default "en"
+}
+
+// utf8BOM is the UTF-8 encoded byte order mark
+const utf8BOM = "\xef\xbb\xbf"
+
+// ParseDelimiter converts names of common delimiters;
+// for use in URL params
+func ParseDelimiter(s string) string {
+	switch strings.ToLower(s) {
+	case "tab", `\t`:
+		return "\t"
+	case "comma":
+		return ","
+	case "semicolon":
+		return ";"
+	case "pipe":
+		return "|"
+	}
+	return s
+}
+
+// comma returns the delimiter as rune
+func (d CSVDialectT) comma() rune {
+	if d.Delimiter == "" {
+		return ';'
+	}
+	r, _ := utf8.DecodeRuneInString(d.Delimiter)
+	return r
+}
+
+func (d CSVDialectT) labelLang() string {
+	if d.LabelLang == "" {
+		return "en"
+	}
+	return d.LabelLang
+}
+
+// templateOrder reorders the columns of superset
+// by page, group and input of the template qBase;
+// the first nStatic columns are kept in front;
+// columns not contained in the template are appended in their original order.
+// Superset() alone depends on the order in which questionnaires are processed;
+// questionnaires of different versions lead to different column orders.
+func templateOrder(qBase *qst.QuestionnaireT, superset []string, nStatic int) []string {
+
+	if qBase == nil || len(qBase.Pages) == 0 || len(superset) < nStatic {
+		return superset
+	}
+
+	present := map[string]bool{}
+	for _, k := range superset[nStatic:] {
+		present[k] = true
+	}
+
+	ret := make([]string, 0, len(superset))
+	ret = append(ret, superset[:nStatic]...)
+
+	_, keys, _ := qBase.KeysValues(false)
+	for _, k := range keys {
+		if present[k] {
+			ret = append(ret, k)
+			delete(present, k) // radios occur several times
+		}
+	}
+	for _, k := range superset[nStatic:] {
+		if present[k] {
+			ret = append(ret, k)
+		}
+	}
+
+	return ret
+}
+
+// valueLabels maps input names to a map of codes to labels;
+// for radio inputs and dropdowns of the template qBase
+func valueLabels(qBase *qst.QuestionnaireT, lang string) map[string]map[string]string {
+	ret := map[string]map[string]string{}
+	if qBase == nil {
+		return ret
+	}
+	for _, pg := range qBase.Pages {
+		for _, gr := range pg.Groups {
+			for _, inp := range gr.Inputs {
+				if inp.Type == "radio" {
+					lbl := qBase.LabelCleanse(inp.Label.TrSilent(lang))
+					if lbl == "" {
+						continue
+					}
+					if ret[inp.Name] == nil {
+						ret[inp.Name] = map[string]string{}
+					}
+					ret[inp.Name][inp.ValueRadio] = lbl
+				}
+				if inp.Type == "dropdown" && inp.DD != nil {
+					for _, opt := range inp.DD.Options {
+						lbl := opt.Val.TrSilent(lang)
+						if lbl == "" {
+							continue
+						}
+						if ret[inp.Name] == nil {
+							ret[inp.Name] = map[string]string{}
+						}
+						ret[inp.Name][opt.Key] = lbl
+					}
+				}
+			}
+		}
+	}
+	return ret
+}
+
+// inputTypes maps columns to the input types of the template qBase
+func inputTypes(qBase *qst.QuestionnaireT, cols []string) map[string]string {
+	inpTypes := map[string]string{}
+	for _, name := range cols {
+		if inp := qBase.ByName(name); inp != nil {
+			inpTypes[name] = inp.Type
+		}
+	}
+	return inpTypes
+}
+
+// applyDialect converts the values of one row
+// for decimal separator and radio labels
+func applyDialect(d CSVDialectT, cols, row []string, inpTypes map[string]string, vLbls map[string]map[string]string) []string {
+
+	ret := make([]string, len(row))
+	for idx, val := range row {
+		col := cols[idx]
+		if d.DecimalSeparator != "" && d.DecimalSeparator != "." && inpTypes[col] == "number" {
+			val = strings.Replace(val, ".", d.DecimalSeparator, 1)
+		}
+		if d.RadioLabels {
+			if lbl, ok := vLbls[col][val]; ok {
+				val = lbl
+			}
+		}
+		ret[idx] = val
+	}
+	return ret
+}
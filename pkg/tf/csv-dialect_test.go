@@ -0,0 +1,85 @@
+package tf
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zew/go-questionnaire/pkg/qst"
+	"github.com/zew/go-questionnaire/pkg/trl"
+)
+
+func TestTemplateOrder(t *testing.T) {
+
+	qBase := &qst.QuestionnaireT{}
+	gr := qBase.AddPage().AddGroup()
+	for _, nm := range []string{"q1", "q2", "q3"} {
+		gr.AddInput().Name = nm
+	}
+	rd := qBase.AddPage().AddGroup()
+	for _, val := range []string{"1", "2"} {
+		inp := rd.AddInput()
+		inp.Name = "rad"
+		inp.Type = "radio"
+		inp.ValueRadio = val
+	}
+
+	tests := []struct {
+		name     string
+		superset []string
+		want     []string
+	}{
+		{
+			name:     "scrambled by versions",
+			superset: []string{"user_id", "page_1", "rad", "q3", "q1", "q2"},
+			want:     []string{"user_id", "page_1", "q1", "q2", "q3", "rad"},
+		},
+		{
+			name:     "unknown columns at the end",
+			superset: []string{"user_id", "page_1", "q9", "q2", "q1"},
+			want:     []string{"user_id", "page_1", "q1", "q2", "q9"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := templateOrder(qBase, tt.superset, 2); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("templateOrder() = \ngot %v, \nwnt %v", got, tt.want)
+			}
+		})
+	}
+
+	// no template - no change
+	superset := []string{"user_id", "b", "a"}
+	if got := templateOrder(&qst.QuestionnaireT{}, superset, 1); !reflect.DeepEqual(got, superset) {
+		t.Errorf("templateOrder() without template = %v", got)
+	}
+}
+
+func TestApplyDialect(t *testing.T) {
+
+	qBase := &qst.QuestionnaireT{}
+	gr := qBase.AddPage().AddGroup()
+	for val, lbl := range map[string]string{"1": "low", "2": "high"} {
+		inp := gr.AddInput()
+		inp.Name = "rad"
+		inp.Type = "radio"
+		inp.ValueRadio = val
+		inp.Label = trl.S{"en": lbl}
+	}
+
+	cols := []string{"user_id", "num", "rad"}
+	row := []string{"1001", "1234.5", "2"}
+	inpTypes := map[string]string{"num": "number", "rad": "radio"}
+	vLbls := valueLabels(qBase, "en")
+
+	d := CSVDialectT{DecimalSeparator: ",", RadioLabels: true}
+	got := applyDialect(d, cols, row, inpTypes, vLbls)
+	want := []string{"1001", "1234,5", "high"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("applyDialect() = %v; want %v", got, want)
+	}
+
+	got = applyDialect(CSVDialectT{}, cols, row, inpTypes, vLbls)
+	if !reflect.DeepEqual(got, row) {
+		t.Errorf("applyDialect() with zero dialect = %v; want %v", got, row)
+	}
+}
@@ -0,0 +1,63 @@
+package tf
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/zew/go-questionnaire/pkg/qst"
+)
+
+// mplSuffixes of the columns per multiple price list
+var mplSuffixes = []string{"switch_row", "switches", "crra_low", "crra_high"}
+
+// mpls returns the multiple price lists of the questionnaires - in order of appearance
+func mpls(qs []*qst.QuestionnaireT) []qst.MPLT {
+	ret := []qst.MPLT{}
+	seen := map[string]bool{}
+	for _, q := range qs {
+		for _, m := range q.MPLs {
+			if !seen[m.Name] {
+				seen[m.Name] = true
+				ret = append(ret, m)
+			}
+		}
+	}
+	return ret
+}
+
+// mplCols returns the column names for the multiple price lists
+func mplCols(ms []qst.MPLT) []string {
+	cols := make([]string, 0, len(mplSuffixes)*len(ms))
+	for _, m := range ms {
+		for _, sfx := range mplSuffixes {
+			cols = append(cols, m.Name+"_"+sfx)
+		}
+	}
+	return cols
+}
+
+// mplVals returns the values for mplCols();
+// empty for incomplete lists and unbounded intervals
+func mplVals(q *qst.QuestionnaireT, ms []qst.MPLT) []string {
+	fl := func(f float64) string {
+		if math.IsNaN(f) {
+			return ""
+		}
+		return fmt.Sprintf("%.2f", f)
+	}
+	vals := make([]string, 0, len(mplSuffixes)*len(ms))
+	for _, m := range ms {
+		res := m.Result(q)
+		if !res.Complete {
+			vals = append(vals, "", "", "", "")
+			continue
+		}
+		vals = append(vals,
+			fmt.Sprint(res.SwitchRow),
+			fmt.Sprint(res.Switches),
+			fl(res.CRRALow),
+			fl(res.CRRAHigh),
+		)
+	}
+	return vals
+}
@@ -0,0 +1,89 @@
+package tf
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zew/go-questionnaire/pkg/qst"
+)
+
+// hasParadata is true, if any questionnaire has paradata;
+// older response files have none - we dont want empty columns
+func hasParadata(qs []*qst.QuestionnaireT) bool {
+	for _, q := range qs {
+		for _, pg := range q.Pages {
+			if pg.Paradata != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// paradataSuffixes of the per page paradata columns
+var paradataSuffixes = []string{"secs", "visits", "failed", "back"}
+
+// paradataCols returns the paradata column names
+// for maxPages pages
+func paradataCols(maxPages int) []string {
+	cols := []string{"device", "time_total"}
+	for iPg := 0; iPg < maxPages; iPg++ {
+		for _, sfx := range paradataSuffixes {
+			cols = append(cols, fmt.Sprintf("page_%v_%v", iPg+1, sfx))
+		}
+	}
+	return cols
+}
+
+// isParadataNumeric is true for all paradata columns except device;
+// including the columns of timed pages
+func isParadataNumeric(col string) bool {
+	if col == "time_total" {
+		return true
+	}
+	if !strings.HasPrefix(col, "page_") {
+		return false
+	}
+	for _, sfx := range append(paradataSuffixes, "elapsed", "timed_out") {
+		if strings.HasSuffix(col, "_"+sfx) {
+			return true
+		}
+	}
+	return false
+}
+
+// paradataVals returns the values for paradataCols();
+// device is "mobile", "desktop" or "mixed"
+func paradataVals(q *qst.QuestionnaireT, maxPages int) []string {
+
+	devices := map[string]bool{}
+	total := 0
+	perPage := make([]string, 0, maxPages*len(paradataSuffixes))
+	for iPg := 0; iPg < maxPages; iPg++ {
+		if iPg > len(q.Pages)-1 || q.Pages[iPg].Paradata == nil {
+			perPage = append(perPage, "", "", "", "")
+			continue
+		}
+		pd := q.Pages[iPg].Paradata
+		if pd.Device != "" {
+			devices[pd.Device] = true
+		}
+		total += pd.TimeOnPage
+		perPage = append(perPage,
+			fmt.Sprint(pd.TimeOnPage),
+			fmt.Sprint(pd.Visits),
+			fmt.Sprint(pd.FailedSubmits),
+			fmt.Sprint(pd.BackNavigations),
+		)
+	}
+
+	device := ""
+	for dv := range devices {
+		device = dv
+	}
+	if len(devices) > 1 {
+		device = "mixed"
+	}
+
+	return append([]string{device, fmt.Sprint(total)}, perPage...)
+}
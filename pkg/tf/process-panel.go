@@ -0,0 +1,219 @@
+package tf
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zew/go-questionnaire/pkg/cloudio"
+	"github.com/zew/go-questionnaire/pkg/qst"
+)
+
+// waveMatrixT holds columns and rows of one wave;
+// the first column is user_id - see extractMatrix()
+type waveMatrixT struct {
+	WaveID  string
+	Cols    []string
+	Rows    [][]string
+	NStatic int // leading columns not from inputs
+}
+
+// byCol returns the row as map of column names to values
+func (wm waveMatrixT) byCol(row []string) map[string]string {
+	ret := make(map[string]string, len(wm.Cols))
+	for idx, col := range wm.Cols {
+		ret[col] = row[idx]
+	}
+	return ret
+}
+
+// lessUserID compares numerically, if possible
+func lessUserID(a, b string) bool {
+	ai, errA := strconv.Atoi(a)
+	bi, errB := strconv.Atoi(b)
+	if errA == nil && errB == nil {
+		return ai < bi
+	}
+	return a < b
+}
+
+// panelLong returns one row per participant and wave;
+// columns are the union of the columns of all waves -
+// inputs in the order of template qBase;
+// inputs missing in a wave remain empty
+func panelLong(waves []waveMatrixT, qBase *qst.QuestionnaireT) ([]string, [][]string) {
+
+	staticByWave := make([][]string, 0, len(waves))
+	inputsByWave := make([][]string, 0, len(waves))
+	for _, wm := range waves {
+		staticByWave = append(staticByWave, wm.Cols[:wm.NStatic])
+		inputsByWave = append(inputsByWave, wm.Cols[wm.NStatic:])
+	}
+	static := Superset(staticByWave)
+	union := append(static, Superset(inputsByWave)...)
+	union = templateOrder(qBase, union, len(static))
+	cols := append([]string{"wave"}, union...)
+
+	type keyedRowT struct {
+		userID  string
+		waveIdx int
+		row     []string
+	}
+	keyed := []keyedRowT{}
+	for waveIdx, wm := range waves {
+		for _, row := range wm.Rows {
+			vals := wm.byCol(row)
+			rec := make([]string, 0, len(cols))
+			rec = append(rec, wm.WaveID)
+			for _, col := range union {
+				rec = append(rec, vals[col])
+			}
+			keyed = append(keyed, keyedRowT{vals["user_id"], waveIdx, rec})
+		}
+	}
+
+	sort.SliceStable(keyed, func(i, j int) bool {
+		if keyed[i].userID != keyed[j].userID {
+			return lessUserID(keyed[i].userID, keyed[j].userID)
+		}
+		return keyed[i].waveIdx < keyed[j].waveIdx
+	})
+
+	rows := make([][]string, 0, len(keyed))
+	for _, k := range keyed {
+		rows = append(rows, k.row)
+	}
+	return cols, rows
+}
+
+// panelWide returns one row per participant;
+// columns of each wave are suffixed by the wave ID;
+// participants missing in a wave have empty values for that wave
+func panelWide(waves []waveMatrixT) ([]string, [][]string) {
+
+	cols := []string{"user_id"}
+	for _, wm := range waves {
+		for _, col := range wm.Cols {
+			if col == "user_id" {
+				continue
+			}
+			cols = append(cols, fmt.Sprintf("%v_%v", col, wm.WaveID))
+		}
+	}
+
+	userIDs := []string{}
+	byUser := map[string][]map[string]string{} // per user - per wave
+	for waveIdx, wm := range waves {
+		for _, row := range wm.Rows {
+			vals := wm.byCol(row)
+			uid := vals["user_id"]
+			if _, ok := byUser[uid]; !ok {
+				userIDs = append(userIDs, uid)
+				byUser[uid] = make([]map[string]string, len(waves))
+			}
+			byUser[uid][waveIdx] = vals
+		}
+	}
+	sort.SliceStable(userIDs, func(i, j int) bool {
+		return lessUserID(userIDs[i], userIDs[j])
+	})
+
+	rows := make([][]string, 0, len(userIDs))
+	for _, uid := range userIDs {
+		rec := make([]string, 0, len(cols))
+		rec = append(rec, uid)
+		for waveIdx, wm := range waves {
+			vals := byUser[uid][waveIdx] // nil map yields empty values
+			for _, col := range wm.Cols {
+				if col == "user_id" {
+					continue
+				}
+				rec = append(rec, vals[col])
+			}
+		}
+		rows = append(rows, rec)
+	}
+	return cols, rows
+}
+
+// ProcessPanel reads several waves of cfgRem.SurveyType from the local bucket,
+// links participants by UserID and writes a panel CSV file;
+// wide: one row per participant, columns suffixed by wave ID;
+// otherwise long: one row per participant and wave - columns ordered by the template of the last wave;
+// inputs added or dropped between waves remain empty where missing;
+// values are converted by cfgRem.CSV with the template of their wave;
+// fetchAll as in RetrieveFromLocal
+func ProcessPanel(cfgRem *RemoteConnConfigT, waveIDs []string, fetchAll string, wide bool) (string, error) {
+
+	layout := "long"
+	if wide {
+		layout = "wide"
+	}
+	fnCSV := path.Join(cfgRem.DownloadDir, fmt.Sprintf("%v-panel-%v.csv", cfgRem.SurveyType, layout))
+
+	dialect := cfgRem.CSV
+	var qBase *qst.QuestionnaireT
+	waves := make([]waveMatrixT, 0, len(waveIDs))
+	for _, waveID := range waveIDs {
+		waveID = strings.TrimSpace(waveID)
+		if waveID == "" {
+			continue
+		}
+		pth := path.Join(qst.BasePath(), cfgRem.SurveyType, waveID)
+		qs, err := RetrieveFromLocal(pth, fetchAll)
+		if err != nil {
+			return fnCSV, fmt.Errorf("could not retrieve wave %v: %w", waveID, err)
+		}
+		cfgWave := *cfgRem
+		cfgWave.WaveID = waveID
+		cols, rows, nStatic := extractMatrix(&cfgWave, qs, false)
+		qBase = baseQuestionnaire(&cfgWave)
+		inpTypes := inputTypes(qBase, cols)
+		vLbls := valueLabels(qBase, dialect.labelLang())
+		for i := range rows {
+			rows[i] = applyDialect(dialect, cols, rows[i], inpTypes, vLbls)
+		}
+		waves = append(waves, waveMatrixT{WaveID: waveID, Cols: cols, Rows: rows, NStatic: nStatic})
+		log.Printf("panel: wave %v - %v participants, %v columns", waveID, len(rows), len(cols))
+	}
+
+	var cols []string
+	var rows [][]string
+	if wide {
+		cols, rows = panelWide(waves)
+	} else {
+		cols, rows = panelLong(waves, qBase)
+	}
+
+	wtr := &bytes.Buffer{}
+	if dialect.BOM {
+		wtr.WriteString(utf8BOM)
+	}
+	csvWtr := csv.NewWriter(wtr)
+	csvWtr.Comma = dialect.comma()
+	if err := csvWtr.Write(cols); err != nil {
+		return fnCSV, fmt.Errorf("error writing header line to csv: %w", err)
+	}
+	if dialect.LabelRow && !wide && qBase != nil { // wide columns are suffixed - no labels
+		if err := csvWtr.Write(columnLabels(qBase, cols, dialect.labelLang())); err != nil {
+			return fnCSV, fmt.Errorf("error writing label line to csv: %w", err)
+		}
+	}
+	if err := csvWtr.WriteAll(rows); err != nil { // WriteAll flushes
+		return fnCSV, fmt.Errorf("error writing records to csv: %w", err)
+	}
+
+	err := cloudio.WriteFile(fnCSV, wtr, 0644)
+	if err != nil {
+		return fnCSV, fmt.Errorf("could not write panel file %v: %w", fnCSV, err)
+	}
+
+	log.Printf("panel of %v waves - %v rows - results in %v", len(waves), len(rows), fnCSV)
+
+	return fnCSV, nil
+}
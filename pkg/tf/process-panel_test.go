@@ -0,0 +1,67 @@
+package tf
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zew/go-questionnaire/pkg/qst"
+)
+
+var testWaves = []waveMatrixT{
+	{
+		WaveID: "2022-01",
+		Cols:   []string{"user_id", "q1", "q2"},
+		Rows: [][]string{
+			{"20", "a", "b"},
+			{"3", "c", "d"},
+		},
+		NStatic: 1,
+	},
+	{
+		WaveID: "2022-02",
+		Cols:   []string{"user_id", "q1", "q3"}, // q2 dropped, q3 added
+		Rows: [][]string{
+			{"3", "e", "f"},
+			{"7", "g", "h"},
+		},
+		NStatic: 1,
+	},
+}
+
+func TestPanelWide(t *testing.T) {
+	cols, rows := panelWide(testWaves)
+	wantCols := []string{"user_id", "q1_2022-01", "q2_2022-01", "q1_2022-02", "q3_2022-02"}
+	wantRows := [][]string{
+		{"3", "c", "d", "e", "f"},
+		{"7", "", "", "g", "h"},
+		{"20", "a", "b", "", ""},
+	}
+	if !reflect.DeepEqual(cols, wantCols) {
+		t.Errorf("cols\ngot %v\nwnt %v", cols, wantCols)
+	}
+	if !reflect.DeepEqual(rows, wantRows) {
+		t.Errorf("rows\ngot %v\nwnt %v", rows, wantRows)
+	}
+}
+
+func TestPanelLong(t *testing.T) {
+	qBase := &qst.QuestionnaireT{}
+	gr := qBase.AddPage().AddGroup()
+	for _, nm := range []string{"q1", "q2", "q3"} {
+		gr.AddInput().Name = nm
+	}
+	cols, rows := panelLong(testWaves, qBase)
+	wantCols := []string{"wave", "user_id", "q1", "q2", "q3"}
+	wantRows := [][]string{
+		{"2022-01", "3", "c", "d", ""},
+		{"2022-02", "3", "e", "", "f"},
+		{"2022-02", "7", "g", "", "h"},
+		{"2022-01", "20", "a", "b", ""},
+	}
+	if !reflect.DeepEqual(cols, wantCols) {
+		t.Errorf("cols\ngot %v\nwnt %v", cols, wantCols)
+	}
+	if !reflect.DeepEqual(rows, wantRows) {
+		t.Errorf("rows\ngot %v\nwnt %v", rows, wantRows)
+	}
+}
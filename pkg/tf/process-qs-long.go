@@ -0,0 +1,219 @@
+package tf
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"path"
+
+	"github.com/zew/go-questionnaire/pkg/cloudio"
+	"github.com/zew/go-questionnaire/pkg/qst"
+)
+
+// LongCols are the columns of the long format export;
+// one row per participant and input
+var LongCols = []string{
+	"user_id",
+	"page",
+	"input",
+	"value",
+	"timestamp",
+	"version",
+}
+
+// LongWriterT writes questionnaires in long format - also called tidy format;
+// rows are written as soon as a questionnaire is passed in;
+// there is no column superset, thus no need to hold all questionnaires in memory
+type LongWriterT struct {
+	csvWtr *csv.Writer
+	rows   int
+}
+
+// NewLongWriter writes the header row to w
+func NewLongWriter(w io.Writer) (*LongWriterT, error) {
+	lw := &LongWriterT{csvWtr: csv.NewWriter(w)}
+	lw.csvWtr.Comma = ';'
+	if err := lw.csvWtr.Write(LongCols); err != nil {
+		return nil, fmt.Errorf("error writing header line to csv: %w", err)
+	}
+	return lw, nil
+}
+
+// Write appends one row for each non-empty response of q;
+// timestamp is the unix time, when the page was first finished
+func (lw *LongWriterT) Write(q *qst.QuestionnaireT) error {
+	for iPg, pg := range q.Pages {
+		ts := ""
+		if !pg.Finished.IsZero() {
+			ts = fmt.Sprintf("%v", pg.Finished.Unix())
+		}
+		for _, gr := range pg.Groups {
+			for _, inp := range gr.Inputs {
+				if inp.IsLayout() || inp.Response == "" {
+					continue
+				}
+				val := inp.Response
+				if inp.Type == "number" {
+					val = qst.DelocalizeNumber(val)
+				}
+				val = qst.EnglishTextAndNumbersOnly(val)
+				rec := []string{
+					q.UserID,
+					fmt.Sprint(iPg + 1),
+					inp.Name,
+					val,
+					ts,
+					fmt.Sprint(q.VersionEffective),
+				}
+				if err := lw.csvWtr.Write(rec); err != nil {
+					return fmt.Errorf("error writing record to csv: %w", err)
+				}
+				lw.rows++
+			}
+		}
+	}
+	return nil
+}
+
+// Flush must be called after the last Write
+func (lw *LongWriterT) Flush() error {
+	lw.csvWtr.Flush()
+	return lw.csvWtr.Error()
+}
+
+// Rows returns the number of data rows written so far
+func (lw *LongWriterT) Rows() int {
+	return lw.rows
+}
+
+// jsonlRecordT is one line of the JSON Lines export
+type jsonlRecordT struct {
+	UserID      string            `json:"user_id"`
+	LangCode    string            `json:"lang_code"`
+	ClosingTime string            `json:"closing_time"`
+	Status      string            `json:"status"`
+	Version     int               `json:"version"`
+	VersionMax  int               `json:"version_max"`
+	Pages       []string          `json:"pages"` // finishing times
+	Responses   map[string]string `json:"responses"`
+
+	Paradata []*qst.ParadataT `json:"paradata,omitempty"` // per page
+
+	Checks map[string]*qst.CheckResultT `json:"checks,omitempty"` // by check name
+}
+
+// WriteJSONL writes q as one line of JSON to w;
+// static columns as in ProcessQs;
+// the responses of all non-layout inputs as name-value object
+func WriteJSONL(w io.Writer, q *qst.QuestionnaireT) error {
+
+	finishes, ks, vs := q.KeysValues(true)
+	closingTime, status := closingTimeAndStatus(q)
+
+	rec := jsonlRecordT{
+		UserID:      q.UserID,
+		LangCode:    q.LangCode,
+		ClosingTime: closingTime,
+		Status:      status,
+		Version:     q.VersionEffective,
+		VersionMax:  q.VersionMax,
+		Pages:       finishes,
+		Responses:   make(map[string]string, len(ks)),
+		Checks:      q.CheckResults,
+	}
+	for i := range ks {
+		rec.Responses[ks[i]] = vs[i]
+	}
+	if hasParadata([]*qst.QuestionnaireT{q}) {
+		for _, pg := range q.Pages {
+			rec.Paradata = append(rec.Paradata, pg.Paradata)
+		}
+	}
+
+	// json.Encoder appends a newline after each value
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(rec); err != nil {
+		return fmt.Errorf("error encoding %v to JSON: %w", q.UserID, err)
+	}
+	return nil
+}
+
+// ProcessQsLong writes the long format CSV file
+// and the JSON Lines file into the download dir;
+// both are streamed into the bucket through a pipe;
+// questionnaires without any answers are skipped
+func ProcessQsLong(cfgRem *RemoteConnConfigT, qs []*qst.QuestionnaireT) (string, string, error) {
+
+	fnCore := path.Join(cfgRem.DownloadDir, fmt.Sprintf("%v-%v", cfgRem.SurveyType, cfgRem.WaveID))
+	fnLong := fnCore + "-long.csv"
+	fnJSONL := fnCore + ".jsonl"
+
+	err := streamToBucket(fnLong, func(w io.Writer) error {
+		lw, err := NewLongWriter(w)
+		if err != nil {
+			return err
+		}
+		for _, q := range qs {
+			if realEntries, _, _ := q.Statistics(); realEntries == 0 {
+				continue
+			}
+			if err := lw.Write(q); err != nil {
+				return err
+			}
+		}
+		return lw.Flush()
+	})
+	if err != nil {
+		return fnLong, fnJSONL, fmt.Errorf("could not write long format file %v: %w", fnLong, err)
+	}
+
+	err = streamToBucket(fnJSONL, func(w io.Writer) error {
+		for _, q := range qs {
+			if realEntries, _, _ := q.Statistics(); realEntries == 0 {
+				continue
+			}
+			if err := WriteJSONL(w, q); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fnLong, fnJSONL, fmt.Errorf("could not write JSON Lines file %v: %w", fnJSONL, err)
+	}
+
+	if len(qs) > 0 && len(qs[0].Conjoints) > 0 {
+		fnConjoint := fnCore + "-conjoint.csv"
+		err = streamToBucket(fnConjoint, func(w io.Writer) error {
+			cw := NewConjointWriter(w)
+			for _, q := range qs {
+				if err := cw.Write(q); err != nil {
+					return err
+				}
+			}
+			return cw.Flush()
+		})
+		if err != nil {
+			return fnLong, fnJSONL, fmt.Errorf("could not write conjoint design matrix %v: %w", fnConjoint, err)
+		}
+	}
+
+	log.Printf("%v questionnaire(s) processed - results in %v and %v", len(qs), fnLong, fnJSONL)
+
+	return fnLong, fnJSONL, nil
+
+}
+
+// streamToBucket connects the output of fn to cloudio.WriteFile
+func streamToBucket(fileName string, fn func(w io.Writer) error) error {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(fn(pw)) // nil error closes regularly
+	}()
+	err := cloudio.WriteFile(fileName, pr, 0644)
+	pr.Close() // unblocks fn, if WriteFile returned early
+	return err
+}
@@ -0,0 +1,80 @@
+package tf
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zew/go-questionnaire/pkg/qst"
+)
+
+func testQuestionnaire() *qst.QuestionnaireT {
+	q := &qst.QuestionnaireT{UserID: "1001", LangCode: "de", VersionEffective: 1, VersionMax: 2}
+	pg := q.AddPage()
+	pg.Finished = time.Unix(1600000000, 0)
+	gr := pg.AddGroup()
+	inp := gr.AddInput()
+	inp.Type = "number"
+	inp.Name = "q1"
+	inp.Response = "1.234,5"
+	inp = gr.AddInput()
+	inp.Type = "textblock"
+	inp.Name = "tb"
+	inp = gr.AddInput()
+	inp.Type = "text"
+	inp.Name = "q2"
+	q.AddPage().AddGroup().AddInput().Name = "q3" // not answered
+	return q
+}
+
+func TestLongWriter(t *testing.T) {
+
+	buf := &bytes.Buffer{}
+	lw, err := NewLongWriter(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lw.Write(testQuestionnaire()); err != nil {
+		t.Fatal(err)
+	}
+	if err := lw.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "user_id;page;input;value;timestamp;version\n" +
+		"1001;1;q1;1234.5;1600000000;1\n"
+	if buf.String() != want {
+		t.Errorf("long format\ngot %q\nwnt %q", buf.String(), want)
+	}
+	if lw.Rows() != 1 {
+		t.Errorf("rows: got %v, wnt 1", lw.Rows())
+	}
+}
+
+func TestWriteJSONL(t *testing.T) {
+
+	buf := &bytes.Buffer{}
+	for i := 0; i < 2; i++ {
+		if err := WriteJSONL(buf, testQuestionnaire()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("want 2 lines; got %v", len(lines))
+	}
+
+	rec := jsonlRecordT{}
+	if err := json.Unmarshal([]byte(lines[0]), &rec); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Status != "1" || rec.ClosingTime != "1600000000" {
+		t.Errorf("status %v, closing time %v", rec.Status, rec.ClosingTime)
+	}
+	if rec.Responses["q1"] != "1234.5" || len(rec.Responses) != 3 {
+		t.Errorf("responses %v", rec.Responses)
+	}
+}
@@ -0,0 +1,99 @@
+package tf
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"path"
+	"strings"
+
+	"github.com/zew/go-questionnaire/pkg/cloudio"
+	"github.com/zew/go-questionnaire/pkg/qst"
+	"github.com/zew/go-questionnaire/pkg/xlsx"
+)
+
+// numeric static columns - see staticCols in extractMatrix()
+var numericStaticCols = map[string]bool{
+	"user_id":      true,
+	"closing_time": true,
+	"status":       true,
+	"version":      true,
+	"version_max":  true,
+}
+
+// ProcessQsXLSX is like ProcessQs, but writes an Excel file;
+// sheet 'data' contains the same columns and rows as the CSV file,
+// with responses to inputs of type number as numeric cells;
+// sheet 'labels' maps the column names to the question texts;
+// header rows are frozen
+func ProcessQsXLSX(cfgRem *RemoteConnConfigT, qs []*qst.QuestionnaireT, saveQSFilesToDownloadDir bool) (string, error) {
+
+	fnXLSX := path.Join(cfgRem.DownloadDir, fmt.Sprintf("%v-%v.xlsx", cfgRem.SurveyType, cfgRem.WaveID))
+
+	cols, rows, _ := extractMatrix(cfgRem, qs, saveQSFilesToDownloadDir)
+
+	qBase := baseQuestionnaire(cfgRem)
+	byNames, _, _ := qBase.LabelsByInputNames(cfgRem.CSV.labelLang())
+
+	numeric := map[string]bool{}
+	for k, v := range numericStaticCols {
+		numeric[k] = v
+	}
+	inpTypes := map[string]string{}
+	for _, name := range cols {
+		inp := qBase.ByName(name)
+		if inp == nil {
+			continue
+		}
+		inpTypes[name] = inp.Type
+		if inp.Type == "number" {
+			numeric[name] = true
+		}
+	}
+
+	wb := xlsx.New()
+
+	data := wb.AddSheet("data")
+	data.FrozenRows = 1
+	data.FrozenCols = 1
+	data.AddStrings(cols...)
+	data.BoldRow(0)
+	for _, row := range rows {
+		cells := make([]xlsx.CellT, len(row))
+		for colIdx, val := range row {
+			if numeric[cols[colIdx]] || isParadataNumeric(cols[colIdx]) {
+				cells[colIdx] = xlsx.Auto(qst.DelocalizeNumber(val)) // 1,5 => 1.5
+			} else {
+				cells[colIdx] = xlsx.Str(val)
+			}
+		}
+		data.AddRow(cells...)
+	}
+
+	lbls := wb.AddSheet("labels")
+	lbls.FrozenRows = 1
+	lbls.ColWidths = map[int]float64{0: 24, 1: 12, 2: 100}
+	lbls.AddStrings("column", "type", "label")
+	lbls.BoldRow(0)
+	for _, name := range cols {
+		lbl := byNames[name]
+		lbl = strings.ReplaceAll(lbl, " -- ", "\n")
+		lbls.AddStrings(name, inpTypes[name], lbl)
+	}
+
+	buf := &bytes.Buffer{}
+	err := wb.Write(buf)
+	if err != nil {
+		return fnXLSX, fmt.Errorf("could not create XLSX: %w", err)
+	}
+
+	err = cloudio.WriteFile(fnXLSX, buf, 0644)
+	if err != nil {
+		return fnXLSX, fmt.Errorf("could not write XLSX file %v: %v", fnXLSX, err)
+	}
+
+	log.Printf("%v questionnaire(s) processed - results in %v", len(qs), fnXLSX)
+
+	return fnXLSX, nil
+
+}
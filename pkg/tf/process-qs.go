@@ -14,15 +14,154 @@ import (
 
 // ProcessQs iterates over qs
 // and extracts columns and values;
-// it is independent of the structure of the questionaires in qs
+// it is independent of the structure of the questionaires in qs;
+// columns follow the order of the template;
+// cfgRem.CSV sets the CSV dialect
 func ProcessQs(cfgRem *RemoteConnConfigT, qs []*qst.QuestionnaireT, saveQSFilesToDownloadDir bool) (string, error) {
 
+	fnCSV := path.Join(cfgRem.DownloadDir, fmt.Sprintf("%v-%v.csv", cfgRem.SurveyType, cfgRem.WaveID))
+
+	allKeysSuperset, valsBySuperset, _ := extractMatrix(cfgRem, qs, saveQSFilesToDownloadDir)
+
+	dialect := cfgRem.CSV
+	qBase := baseQuestionnaire(cfgRem)
+	lbls := columnLabels(qBase, allKeysSuperset, dialect.labelLang())
+
+	inpTypes := inputTypes(qBase, allKeysSuperset)
+	vLbls := valueLabels(qBase, dialect.labelLang())
+
+	// Data into CSV matrix...
+	var wtr = new(bytes.Buffer)
+	if dialect.BOM {
+		wtr.WriteString(utf8BOM)
+	}
+	csvWtr := csv.NewWriter(wtr)
+	csvWtr.Comma = dialect.comma()
+	if err := csvWtr.Write(allKeysSuperset); err != nil {
+		return fnCSV, fmt.Errorf("error writing header line to csv: %w", err)
+	}
+	if dialect.LabelRow {
+		if err := csvWtr.Write(lbls); err != nil {
+			return fnCSV, fmt.Errorf("error writing label line to csv: %w", err)
+		}
+	}
+	for _, record := range valsBySuperset {
+		record = applyDialect(dialect, allKeysSuperset, record, inpTypes, vLbls)
+		if err := csvWtr.Write(record); err != nil {
+			return fnCSV, fmt.Errorf("error writing record to csv: %w", err)
+		}
+	}
+
+	// Write any buffered data to the underlying writer (standard output).
+	csvWtr.Flush()
+	if err := csvWtr.Error(); err != nil {
+		return fnCSV, fmt.Errorf("error flushing csv to response writer: %w", err)
+	}
+
+	err := cloudio.WriteFile(fnCSV, wtr, 0644)
+	if err != nil {
+		return fnCSV, fmt.Errorf("could not write CSV file %v: %v", fnCSV, err)
+	}
+
+	//
+	//
+	//
+	// separate CSV file with labels
+	if len(qs) > 0 {
+
+		// enclosing every cell value in double quotes allows to include newlines
+		for idx := range lbls {
+			lbls[idx] = "\"" + lbls[idx] + "\""
+		}
+
+		buf := &bytes.Buffer{}
+		buf.WriteString(strings.Join(allKeysSuperset, ";"))
+		buf.WriteString("\n")
+		buf.WriteString(strings.Join(lbls, ";"))
+
+		fnLabels := strings.ReplaceAll(fnCSV, ".csv", "-labels.csv")
+		err = cloudio.WriteFile(fnLabels, buf, 0644)
+		if err != nil {
+			log.Printf("writing labels file failed: %v - error %v", fnLabels, err)
+		}
+
+	}
+
+	log.Printf(
+		"\n\nRegular finish. %v questionnaire(s) processed\nresults in %v\n\n",
+		len(qs), fnCSV,
+	)
+
+	return fnCSV, nil
+
+}
+
+// columnLabels returns the question texts for cols;
+// column name, if there is no question text;
+// texts are in language lc
+func columnLabels(qBase *qst.QuestionnaireT, cols []string, lc string) []string {
+
+	// excelNL is the inside cell newline character for Excel under Windows
+	const excelNL = string(rune(int32(10)))
+
+	byNames, _, _ := qBase.LabelsByInputNames(lc)
+	lbls := make([]string, 0, len(cols))
+	for _, name := range cols {
+		if lbl, ok := byNames[name]; ok {
+			if !strings.HasPrefix(lbl, excelNL) {
+				lbl += excelNL
+			}
+			lbls = append(lbls, strings.ReplaceAll(lbl, " -- ", excelNL))
+		} else {
+			lbls = append(lbls, name)
+		}
+	}
+	return lbls
+}
+
+// baseQuestionnaire loads the questionnaire template
+// for survey and wave of cfgRem;
+// on error, an empty questionnaire is returned
+func baseQuestionnaire(cfgRem *RemoteConnConfigT) *qst.QuestionnaireT {
+	fnCore := cfgRem.SurveyType + "-" + cfgRem.WaveID
+	pthBase := path.Join(qst.BasePath(), fnCore+".json")
+	qBase, err := qst.Load1(pthBase)
+	if err != nil {
+		log.Printf("loading base questionnaire error %v", err)
+	}
+	return qBase
+}
+
+// closingTimeAndStatus returns the unix time of closing and status 2 - or the status of the end state, see qst.EndStatus;
+// or the unix time of the last finished page and status 1;
+// or empty and status 0
+func closingTimeAndStatus(q *qst.QuestionnaireT) (string, string) {
+	if status, ok := qst.EndStatus[q.EndState]; ok && !q.ClosingTime.IsZero() {
+		return fmt.Sprintf("%v", q.ClosingTime.Unix()), status
+	}
+	if !q.ClosingTime.IsZero() {
+		return fmt.Sprintf("%v", q.ClosingTime.Unix()), "2"
+	}
+	for i2 := len(q.Pages) - 1; i2 > -1; i2-- {
+		if !q.Pages[i2].Finished.IsZero() {
+			return fmt.Sprintf("%v", q.Pages[i2].Finished.Unix()), "1"
+		}
+	}
+	return "", "0"
+}
+
+// extractMatrix computes the column names
+// and the rows of values of all questionnaires;
+// questionnaires without any answers are skipped;
+// if saveQSFilesToDownloadDir, the questionnaires are saved
+// to the download dir - or to subdir 'empty';
+// nStatic is the number of leading columns not from inputs
+func extractMatrix(cfgRem *RemoteConnConfigT, qs []*qst.QuestionnaireT, saveQSFilesToDownloadDir bool) (cols []string, rows [][]string, nStatic int) {
+
 	if cfgRem.DownloadDir == "" {
 		log.Panicf("download dir cannot be empty")
 	}
 
-	fnCSV := path.Join(cfgRem.DownloadDir, fmt.Sprintf("%v-%v.csv", cfgRem.SurveyType, cfgRem.WaveID))
-
 	dirFull := path.Join(cfgRem.DownloadDir, cfgRem.SurveyType, cfgRem.WaveID)
 	dirEmpty := path.Join(dirFull, "empty")
 
@@ -53,6 +192,20 @@ func ProcessQs(cfgRem *RemoteConnConfigT, qs []*qst.QuestionnaireT, saveQSFilesT
 	for iPg := 0; iPg < maxPages; iPg++ {
 		staticCols = append(staticCols, fmt.Sprintf("page_%v", iPg+1))
 	}
+	withParadata := hasParadata(qs)
+	if withParadata {
+		staticCols = append(staticCols, paradataCols(maxPages)...)
+	}
+	checks := checkNames(qs)
+	staticCols = append(staticCols, checkCols(checks)...)
+	mplsAll := mpls(qs)
+	staticCols = append(staticCols, mplCols(mplsAll)...)
+	timed := timedPages(qs)
+	staticCols = append(staticCols, timedCols(timed)...)
+	withConsent := hasConsent(qs)
+	if withConsent {
+		staticCols = append(staticCols, consentCols...)
+	}
 
 	nonEmpty := 0
 	empty := 0
@@ -136,23 +289,10 @@ func ProcessQs(cfgRem *RemoteConnConfigT, qs []*qst.QuestionnaireT, saveQSFilesT
 		// Prepare columns...
 		finishes, ks, vs := q.KeysValues(true)
 
-		ks = append(staticCols, ks...)
+		ks = append(append([]string{}, staticCols...), ks...) // copy - staticCols may have spare capacity
 		keysByQ = append(keysByQ, ks)
 
-		formattedClosingTime := ""
-		status := "0"
-		if qs[i].ClosingTime.IsZero() {
-			for i2 := len(qs[i].Pages) - 1; i2 > -1; i2-- {
-				if !qs[i].Pages[i2].Finished.IsZero() {
-					formattedClosingTime = fmt.Sprintf("%v", qs[i].Pages[i2].Finished.Unix())
-					status = "1"
-					break
-				}
-			}
-		} else {
-			formattedClosingTime = fmt.Sprintf("%v", qs[i].ClosingTime.Unix())
-			status = "2"
-		}
+		formattedClosingTime, status := closingTimeAndStatus(q)
 
 		// equivalent staticCols...
 		prepend := []string{
@@ -172,12 +312,21 @@ func ProcessQs(cfgRem *RemoteConnConfigT, qs []*qst.QuestionnaireT, saveQSFilesT
 				prepend = append(prepend, "n.a.") // response had less than max pages - not finishing time
 			}
 		}
+		if withParadata {
+			prepend = append(prepend, paradataVals(q, maxPages)...)
+		}
+		prepend = append(prepend, checkVals(q, checks)...)
+		prepend = append(prepend, mplVals(q, mplsAll)...)
+		prepend = append(prepend, timedVals(q, timed)...)
+		if withConsent {
+			prepend = append(prepend, consentVals(q)...)
+		}
 		vs = append(prepend, vs...)
 		valsByQ = append(valsByQ, vs)
 
 	} // forr questionnaires
 
-	allKeysSuperset := Superset(keysByQ)
+	allKeysSuperset := templateOrder(baseQuestionnaire(cfgRem), Superset(keysByQ), len(staticCols))
 
 	allKeysSSMap := map[string]int{}
 	for idx, v := range allKeysSuperset {
@@ -202,84 +351,11 @@ func ProcessQs(cfgRem *RemoteConnConfigT, qs []*qst.QuestionnaireT, saveQSFilesT
 		}
 	}
 
-	// Data into CSV matrix...
-	var wtr = new(bytes.Buffer)
-	csvWtr := csv.NewWriter(wtr)
-	csvWtr.Comma = ';'
-	if err := csvWtr.Write(allKeysSuperset); err != nil {
-		return fnCSV, fmt.Errorf("error writing header line to csv: %w", err)
-	}
-	for _, record := range valsBySuperset {
-		if err := csvWtr.Write(record); err != nil {
-			return fnCSV, fmt.Errorf("error writing record to csv: %w", err)
-		}
-	}
-
-	// Write any buffered data to the underlying writer (standard output).
-	csvWtr.Flush()
-	if err := csvWtr.Error(); err != nil {
-		return fnCSV, fmt.Errorf("error flushing csv to response writer: %w", err)
-	}
-
-	err := cloudio.WriteFile(fnCSV, wtr, 0644)
-	if err != nil {
-		return fnCSV, fmt.Errorf("could not write CSV file %v: %v", fnCSV, err)
-	}
-
-	//
-	//
-	//
-	// separate CSV file with labels
-	if len(qs) > 0 {
-
-		nams := []string{} // input names
-		lbls := []string{} // input labels
-
-		fnCore := cfgRem.SurveyType + "-" + cfgRem.WaveID
-		pthBase := path.Join(qst.BasePath(), fnCore+".json")
-		qBase, err := qst.Load1(pthBase)
-		if err != nil {
-			log.Printf("loading base questionnaire error %v", err)
-		}
-
-		// enclosing every cell value in double quotes allows to include newlines
-		// excelWindowsNewline is the inside cell newlince character for Excel under Windows
-		// excel newline for windows - inside cells
-		const excelNL = string(rune(int32(10)))
-
-		// copy(staticLabels, staticCols)
-		byNames, _, _ := qBase.LabelsByInputNames()
-		for _, name := range allKeysSuperset {
-			nams = append(nams, name)
-			if lbl, ok := byNames[name]; ok {
-				if !strings.HasPrefix(lbl, excelNL) {
-					lbl += excelNL
-				}
-				lbl = "\"" + strings.ReplaceAll(lbl, " -- ", excelNL) + "\""
-				lbls = append(lbls, lbl)
-			} else {
-				lbls = append(lbls, name)
-			}
-		}
-
-		buf := &bytes.Buffer{}
-		buf.WriteString(strings.Join(nams, ";"))
-		buf.WriteString("\n")
-		buf.WriteString(strings.Join(lbls, ";"))
-
-		fnLabels := strings.ReplaceAll(fnCSV, ".csv", "-labels.csv")
-		err = cloudio.WriteFile(fnLabels, buf, 0644)
-		if err != nil {
-			log.Printf("writing labels file failed: %v - error %v", fnLabels, err)
-		}
-
-	}
-
 	log.Printf(
-		"\n\nRegular finish. %v questionnaire(s) processed\n%v non empty - %v empty\nresults in %v\n\n", len(qs),
-		nonEmpty, empty, fnCSV,
+		"%v questionnaire(s) - %v non empty - %v empty",
+		len(qs), nonEmpty, empty,
 	)
 
-	return fnCSV, nil
+	return allKeysSuperset, valsBySuperset, len(staticCols)
 
 }
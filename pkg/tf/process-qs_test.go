@@ -0,0 +1,28 @@
+package tf
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zew/go-questionnaire/pkg/qst"
+	"github.com/zew/go-questionnaire/pkg/trl"
+)
+
+func TestColumnLabels(t *testing.T) {
+
+	qBase := &qst.QuestionnaireT{}
+	inp := qBase.AddPage().AddGroup().AddInput()
+	inp.Name = "q1"
+	inp.Type = "text"
+	inp.Label = trl.S{"de": "Frage", "en": "Question"}
+
+	cols := []string{"user_id", "q1"}
+	for lc, want := range map[string][]string{
+		"en": {"user_id", "Question\n"},
+		"de": {"user_id", "Frage\n"},
+	} {
+		if got := columnLabels(qBase, cols, lc); !reflect.DeepEqual(got, want) {
+			t.Errorf("columnLabels(%v) = %q; want %q", lc, got, want)
+		}
+	}
+}
@@ -41,11 +41,30 @@ func RetrieveFromLocal(
 
 	var qs []*qst.QuestionnaireT
 
+	err := RetrieveEach(pth, fetchAll, func(q *qst.QuestionnaireT) error {
+		qs = append(qs, q)
+		return nil
+	})
+
+	return qs, err
+
+}
+
+// RetrieveEach is like RetrieveFromLocal,
+// but hands each questionnaire to fn instead of collecting them;
+// memory use does not grow with the number of questionnaires;
+// an error from fn stops the iteration
+func RetrieveEach(
+	pth string,
+	fetchAll string,
+	fn func(q *qst.QuestionnaireT) error,
+) error {
+
 	log.Printf("transferrer-endpoint: reading from dir  %v", pth)
 	infos, err := cloudio.ReadDir(pth)
 	// infos, err := dir.Readdir(-1)
 	if err != nil {
-		return qs, fmt.Errorf("Could not read directory; %w", err)
+		return fmt.Errorf("Could not read directory; %w", err)
 	}
 	log.Printf("transferrer-endpoint: found %v files", len(*infos))
 
@@ -66,7 +85,7 @@ func RetrieveFromLocal(
 		q, err := qst.Load1(pth)
 		if err != nil {
 			s := fmt.Sprintf("iter %3v: No file %v found", i, pth)
-			return qs, fmt.Errorf(s+" - %w", err)
+			return fmt.Errorf(s+" - %w", err)
 		}
 
 		/*
@@ -86,11 +105,13 @@ func RetrieveFromLocal(
 			}
 		}
 
-		qs = append(qs, q)
+		if err := fn(q); err != nil {
+			return fmt.Errorf("iter %3v: processing %v failed: %w", i, pth, err)
+		}
 
 	}
 
-	return qs, nil
+	return nil
 
 }
 
@@ -0,0 +1,49 @@
+package tf
+
+import (
+	"fmt"
+
+	"github.com/zew/go-questionnaire/pkg/qst"
+)
+
+// timedPages returns the indexes of all pages with time limits
+func timedPages(qs []*qst.QuestionnaireT) []int {
+	mp := map[int]bool{}
+	idxs := []int{}
+	for _, q := range qs {
+		for iPg, pg := range q.Pages {
+			if (pg.MinSeconds > 0 || pg.MaxSeconds > 0) && !mp[iPg] {
+				mp[iPg] = true
+				idxs = append(idxs, iPg)
+			}
+		}
+	}
+	return idxs
+}
+
+// timedCols returns two columns per timed page - elapsed seconds and timed out
+func timedCols(idxs []int) []string {
+	cols := make([]string, 0, 2*len(idxs))
+	for _, iPg := range idxs {
+		cols = append(cols, fmt.Sprintf("page_%v_elapsed", iPg+1), fmt.Sprintf("page_%v_timed_out", iPg+1))
+	}
+	return cols
+}
+
+// timedVals returns the values for timedCols()
+func timedVals(q *qst.QuestionnaireT, idxs []int) []string {
+	vals := make([]string, 0, 2*len(idxs))
+	for _, iPg := range idxs {
+		if iPg > len(q.Pages)-1 || q.Pages[iPg].Paradata == nil {
+			vals = append(vals, "", "")
+			continue
+		}
+		pd := q.Pages[iPg].Paradata
+		timedOut := "0"
+		if pd.TimedOut {
+			timedOut = "1"
+		}
+		vals = append(vals, fmt.Sprint(pd.Elapsed), timedOut)
+	}
+	return vals
+}
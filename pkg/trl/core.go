@@ -375,4 +375,300 @@ var coreTranslations = Map{
 		</ul>
 		`,
 	},
+	"previous_wave_hint": {
+		"de": "Vorige Umfrage: %v",
+		"en": "Last survey: %v",
+		"es": "Última encuesta: %v",
+		"fr": "Dernière enquête: %v",
+		"it": "Ultimo sondaggio: %v",
+		"pl": "Ostatnia ankieta: %v",
+	},
+	"previous_wave_choice": {
+		"de": "(vorige Umfrage)",
+		"en": "(last survey)",
+		"es": "(última encuesta)",
+		"fr": "(dernière enquête)",
+		"it": "(ultimo sondaggio)",
+		"pl": "(ostatnia ankieta)",
+	},
+	"feedback_after_closing": {
+		"de": "Der Vergleich mit den anderen Teilnehmern wird nach Abschluss der Umfrage angezeigt.",
+		"en": "The comparison with other participants is shown after you have finished the survey.",
+		"es": "La comparación con otros participantes se muestra después de terminar la encuesta.",
+		"fr": "La comparaison avec les autres participants s'affiche après la fin de l'enquête.",
+		"it": "Il confronto con gli altri partecipanti viene mostrato dopo aver completato il sondaggio.",
+		"pl": "Porównanie z innymi uczestnikami zostanie pokazane po zakończeniu ankiety.",
+	},
+	"feedback_too_few": {
+		"de": "Noch zu wenige Antworten für einen Vergleich.",
+		"en": "Not enough responses for a comparison yet.",
+		"es": "Aún no hay suficientes respuestas para una comparación.",
+		"fr": "Pas encore assez de réponses pour une comparaison.",
+		"it": "Non ci sono ancora abbastanza risposte per un confronto.",
+		"pl": "Za mało odpowiedzi do porównania.",
+	},
+	"feedback_own": {
+		"de": "Ihre Antwort",
+		"en": "Your answer",
+		"es": "Su respuesta",
+		"fr": "Votre réponse",
+		"it": "La sua risposta",
+		"pl": "Twoja odpowiedź",
+	},
+	"feedback_own_choice": {
+		"de": "(Ihre Antwort)",
+		"en": "(your answer)",
+		"es": "(su respuesta)",
+		"fr": "(votre réponse)",
+		"it": "(la sua risposta)",
+		"pl": "(twoja odpowiedź)",
+	},
+	"feedback_mean": {
+		"de": "Mittelwert",
+		"en": "Mean",
+		"es": "Media",
+		"fr": "Moyenne",
+		"it": "Media",
+		"pl": "Średnia",
+	},
+	"feedback_median": {
+		"de": "Median",
+		"en": "Median",
+		"es": "Mediana",
+		"fr": "Médiane",
+		"it": "Mediana",
+		"pl": "Mediana",
+	},
+	"feedback_n": {
+		"de": "Anzahl Antworten",
+		"en": "Number of responses",
+		"es": "Número de respuestas",
+		"fr": "Nombre de réponses",
+		"it": "Numero di risposte",
+		"pl": "Liczba odpowiedzi",
+	},
+	"feedback_position": {
+		"de": "%.0f%% der Teilnehmer haben einen niedrigeren Wert angegeben.",
+		"en": "%.0f%% of participants gave a lower value.",
+		"es": "El %.0f%% de los participantes indicó un valor inferior.",
+		"fr": "%.0f%% des participants ont indiqué une valeur inférieure.",
+		"it": "Il %.0f%% dei partecipanti ha indicato un valore inferiore.",
+		"pl": "%.0f%% uczestników podało niższą wartość.",
+	},
+	"report_title": {
+		"de": "Ihre Antworten",
+		"en": "Your answers",
+		"es": "Sus respuestas",
+		"fr": "Vos réponses",
+		"it": "Le sue risposte",
+		"pl": "Twoje odpowiedzi",
+	},
+	"report_print": {
+		"de": "Drucken oder als PDF speichern",
+		"en": "Print or save as PDF",
+		"es": "Imprimir o guardar como PDF",
+		"fr": "Imprimer ou enregistrer en PDF",
+		"it": "Stampa o salva come PDF",
+		"pl": "Drukuj lub zapisz jako PDF",
+	},
+	"report_link": {
+		"de": "<a href='%v' target='_blank'>Ihre Antworten zum Ausdrucken</a>",
+		"en": "<a href='%v' target='_blank'>Printable copy of your answers</a>",
+		"es": "<a href='%v' target='_blank'>Copia imprimible de sus respuestas</a>",
+		"fr": "<a href='%v' target='_blank'>Copie imprimable de vos réponses</a>",
+		"it": "<a href='%v' target='_blank'>Copia stampabile delle sue risposte</a>",
+		"pl": "<a href='%v' target='_blank'>Twoje odpowiedzi do wydruku</a>",
+	},
+	"report_after_closing": {
+		"de": "Die Übersicht Ihrer Antworten ist nach Abschluss des Fragebogens verfügbar.",
+		"en": "The overview of your answers is available after you have finished the questionnaire.",
+		"es": "El resumen de sus respuestas estará disponible después de terminar el cuestionario.",
+		"fr": "Le récapitulatif de vos réponses est disponible après avoir terminé le questionnaire.",
+		"it": "Il riepilogo delle sue risposte è disponibile dopo aver completato il questionario.",
+		"pl": "Podsumowanie odpowiedzi będzie dostępne po zakończeniu ankiety.",
+	},
+	"end_state_screened_out": {
+		"de": "Vielen Dank für Ihr Interesse. Leider gehören Sie nicht zur Zielgruppe dieser Umfrage.",
+		"en": "Thank you for your interest. Unfortunately, you are not part of the target group of this survey.",
+		"es": "Gracias por su interés. Lamentablemente, usted no forma parte del grupo objetivo de esta encuesta.",
+		"fr": "Merci de votre intérêt. Malheureusement, vous ne faites pas partie du groupe cible de cette enquête.",
+		"it": "Grazie per l'interesse. Purtroppo non fa parte del gruppo target di questo sondaggio.",
+		"pl": "Dziękujemy za zainteresowanie. Niestety nie należysz do grupy docelowej tej ankiety.",
+	},
+	"end_state_over_quota": {
+		"de": "Vielen Dank für Ihr Interesse. Für Ihre Gruppe liegen bereits genügend Antworten vor.",
+		"en": "Thank you for your interest. We already have enough responses for your group.",
+		"es": "Gracias por su interés. Ya tenemos suficientes respuestas para su grupo.",
+		"fr": "Merci de votre intérêt. Nous avons déjà suffisamment de réponses pour votre groupe.",
+		"it": "Grazie per l'interesse. Abbiamo già abbastanza risposte per il suo gruppo.",
+		"pl": "Dziękujemy za zainteresowanie. Mamy już wystarczająco dużo odpowiedzi z Twojej grupy.",
+	},
+	"end_state_attention_failed": {
+		"de": "Die Umfrage wurde beendet, da Kontrollfragen nicht korrekt beantwortet wurden.",
+		"en": "The survey has ended, because control questions were not answered correctly.",
+		"es": "La encuesta ha terminado porque las preguntas de control no se respondieron correctamente.",
+		"fr": "L'enquête est terminée, car les questions de contrôle n'ont pas été correctement répondues.",
+		"it": "Il sondaggio è terminato perché le domande di controllo non sono state risposte correttamente.",
+		"pl": "Ankieta została zakończona, ponieważ pytania kontrolne nie zostały poprawnie udzielone.",
+	},
+	"end_state_timed_out": {
+		"de": "Die Umfrage wurde beendet, da die verfügbare Zeit abgelaufen ist.",
+		"en": "The survey has ended, because the available time has run out.",
+		"es": "La encuesta ha terminado porque se ha agotado el tiempo disponible.",
+		"fr": "L'enquête est terminée, car le temps imparti est écoulé.",
+		"it": "Il sondaggio è terminato perché il tempo a disposizione è scaduto.",
+		"pl": "Ankieta została zakończona, ponieważ upłynął dostępny czas.",
+	},
+	"check_failed": {
+		"de": "Mindestens eine Ihrer Antworten ist falsch. Bitte lesen Sie die Anleitung und die Fragen noch einmal genau.",
+		"en": "At least one of your answers is wrong. Please read the instructions and the questions again carefully.",
+		"es": "Al menos una de sus respuestas es incorrecta. Por favor, lea de nuevo atentamente las instrucciones y las preguntas.",
+		"fr": "Au moins une de vos réponses est fausse. Veuillez relire attentivement les instructions et les questions.",
+		"it": "Almeno una delle sue risposte è errata. Legga di nuovo attentamente le istruzioni e le domande.",
+		"pl": "Co najmniej jedna z Twoich odpowiedzi jest błędna. Przeczytaj ponownie uważnie instrukcję i pytania.",
+	},
+	"check_attempt": {
+		"de": "Versuch %v von %v.",
+		"en": "Attempt %v of %v.",
+		"es": "Intento %v de %v.",
+		"fr": "Essai %v sur %v.",
+		"it": "Tentativo %v di %v.",
+		"pl": "Próba %v z %v.",
+	},
+	"check_incomplete": {
+		"de": "Bitte beantworten Sie alle Kontrollfragen.",
+		"en": "Please answer all control questions.",
+		"es": "Por favor, responda a todas las preguntas de control.",
+		"fr": "Veuillez répondre à toutes les questions de contrôle.",
+		"it": "Risponda a tutte le domande di controllo.",
+		"pl": "Prosimy odpowiedzieć na wszystkie pytania kontrolne.",
+	},
+	"mpl_switch_at": {
+		"de": "Zeile %v: Option A %v oder Option B %v - ab dieser Zeile wähle ich Option B",
+		"en": "Row %v: option A %v or option B %v - from this row on, I choose option B",
+		"es": "Fila %v: opción A %v u opción B %v - a partir de esta fila, elijo la opción B",
+		"fr": "Ligne %v : option A %v ou option B %v - à partir de cette ligne, je choisis l'option B",
+		"it": "Riga %v: opzione A %v o opzione B %v - da questa riga in poi, scelgo l'opzione B",
+		"pl": "Wiersz %v: opcja A %v lub opcja B %v - od tego wiersza wybieram opcję B",
+	},
+	"mpl_never_switch": {
+		"de": "Ich wähle in allen Zeilen Option A",
+		"en": "I choose option A in all rows",
+		"es": "Elijo la opción A en todas las filas",
+		"fr": "Je choisis l'option A dans toutes les lignes",
+		"it": "Scelgo l'opzione A in tutte le righe",
+		"pl": "We wszystkich wierszach wybieram opcję A",
+	},
+	"mpl_multiple_switching": {
+		"de": "Bitte wechseln Sie höchstens einmal von Option A zu Option B.",
+		"en": "Please switch from option A to option B at most once.",
+		"es": "Por favor, cambie de la opción A a la opción B como máximo una vez.",
+		"fr": "Veuillez passer de l'option A à l'option B au plus une fois.",
+		"it": "Passi dall'opzione A all'opzione B al massimo una volta.",
+		"pl": "Prosimy zmienić opcję A na opcję B co najwyżej raz.",
+	},
+	"conjoint_option": {
+		"de": "Option %v",
+		"en": "Option %v",
+		"es": "Opción %v",
+		"fr": "Option %v",
+		"it": "Opzione %v",
+		"pl": "Opcja %v",
+	},
+	"payout_pending": {
+		"de": "Ihre Auszahlung wird berechnet, sobald Sie die Umfrage abgeschlossen haben.",
+		"en": "Your payout will be computed once you have completed the survey.",
+		"es": "Su pago se calculará una vez que haya completado la encuesta.",
+		"fr": "Votre paiement sera calculé une fois l'enquête terminée.",
+		"it": "Il suo pagamento sarà calcolato una volta completato il sondaggio.",
+		"pl": "Twoja wypłata zostanie obliczona po ukończeniu ankiety.",
+	},
+	"payout_result": {
+		"de": "Eine Ihrer Entscheidungen wurde zufällig für die Auszahlung ausgewählt. Ihre Auszahlung beträgt %v %v.",
+		"en": "One of your decisions was randomly selected for payment. Your payout amounts to %v %v.",
+		"es": "Una de sus decisiones fue seleccionada al azar para el pago. Su pago asciende a %v %v.",
+		"fr": "L'une de vos décisions a été tirée au sort pour le paiement. Votre paiement s'élève à %v %v.",
+		"it": "Una delle sue decisioni è stata selezionata a caso per il pagamento. Il suo pagamento ammonta a %v %v.",
+		"pl": "Jedna z Twoich decyzji została losowo wybrana do wypłaty. Twoja wypłata wynosi %v %v.",
+	},
+	"page_time_min": {
+		"de": "Bitte nehmen Sie sich Zeit. Sie können in %v Sekunden fortfahren.",
+		"en": "Please take your time. You can continue in %v seconds.",
+		"es": "Por favor, tómese su tiempo. Podrá continuar en %v segundos.",
+		"fr": "Veuillez prendre votre temps. Vous pourrez continuer dans %v secondes.",
+		"it": "Si prenda il suo tempo. Potrà continuare tra %v secondi.",
+		"pl": "Prosimy nie spieszyć się. Możesz kontynuować za %v sekund.",
+	},
+	"page_time_remaining": {
+		"de": "Verbleibende Zeit in Sekunden:",
+		"en": "Remaining time in seconds:",
+		"es": "Tiempo restante en segundos:",
+		"fr": "Temps restant en secondes :",
+		"it": "Tempo rimanente in secondi:",
+		"pl": "Pozostały czas w sekundach:",
+	},
+	"page_time_over": {
+		"de": "Die Zeit ist abgelaufen. Ihre Antworten können nicht mehr geändert werden.",
+		"en": "Time is up. Your answers can no longer be changed.",
+		"es": "Se acabó el tiempo. Sus respuestas ya no se pueden modificar.",
+		"fr": "Le temps est écoulé. Vos réponses ne peuvent plus être modifiées.",
+		"it": "Il tempo è scaduto. Le sue risposte non possono più essere modificate.",
+		"pl": "Czas minął. Twoich odpowiedzi nie można już zmienić.",
+	},
+	"consent_accept": {
+		"de": "Ich habe die Informationen gelesen und willige in die Teilnahme ein.",
+		"en": "I have read the information and consent to participate.",
+		"es": "He leído la información y doy mi consentimiento para participar.",
+		"fr": "J'ai lu les informations et je consens à participer.",
+		"it": "Ho letto le informazioni e acconsento a partecipare.",
+		"pl": "Przeczytałem/am informacje i wyrażam zgodę na udział.",
+	},
+	"consent_given": {
+		"de": "Sie haben am %v eingewilligt.",
+		"en": "You gave your consent on %v.",
+		"es": "Usted dio su consentimiento el %v.",
+		"fr": "Vous avez donné votre consentement le %v.",
+		"it": "Ha dato il suo consenso il %v.",
+		"pl": "Zgoda została wyrażona w dniu %v.",
+	},
+	"consent_withdraw": {
+		"de": "Einwilligung widerrufen und Daten löschen",
+		"en": "Withdraw consent and delete data",
+		"es": "Retirar el consentimiento y eliminar los datos",
+		"fr": "Retirer le consentement et supprimer les données",
+		"it": "Revocare il consenso e cancellare i dati",
+		"pl": "Wycofaj zgodę i usuń dane",
+	},
+	"consent_withdraw_confirm": {
+		"de": "Wollen Sie Ihre Einwilligung widerrufen? Alle Ihre Antworten werden unwiderruflich gelöscht.",
+		"en": "Do you want to withdraw your consent? All your answers will be deleted irrevocably.",
+		"es": "¿Desea retirar su consentimiento? Todas sus respuestas se eliminarán de forma irrevocable.",
+		"fr": "Voulez-vous retirer votre consentement ? Toutes vos réponses seront supprimées de manière irrévocable.",
+		"it": "Vuole revocare il suo consenso? Tutte le sue risposte saranno cancellate in modo irrevocabile.",
+		"pl": "Czy chcesz wycofać zgodę? Wszystkie Twoje odpowiedzi zostaną nieodwracalnie usunięte.",
+	},
+	"consent_withdrawn": {
+		"de": "Ihre Einwilligung wurde widerrufen. Ihre Daten wurden gelöscht.",
+		"en": "Your consent has been withdrawn. Your data has been deleted.",
+		"es": "Su consentimiento ha sido retirado. Sus datos han sido eliminados.",
+		"fr": "Votre consentement a été retiré. Vos données ont été supprimées.",
+		"it": "Il suo consenso è stato revocato. I suoi dati sono stati cancellati.",
+		"pl": "Twoja zgoda została wycofana. Twoje dane zostały usunięte.",
+	},
+	"consent_withdraw_failed": {
+		"de": "Ihre Daten konnten nicht vollständig gelöscht werden. Bitte versuchen Sie es erneut oder wenden Sie sich an uns.",
+		"en": "Your data could not be deleted completely. Please try again or contact us.",
+		"es": "Sus datos no pudieron eliminarse por completo. Inténtelo de nuevo o póngase en contacto con nosotros.",
+		"fr": "Vos données n'ont pas pu être entièrement supprimées. Veuillez réessayer ou nous contacter.",
+		"it": "Non è stato possibile cancellare completamente i suoi dati. La preghiamo di riprovare o di contattarci.",
+		"pl": "Nie udało się całkowicie usunąć Twoich danych. Spróbuj ponownie lub skontaktuj się z nami.",
+	},
+	"save_conflict": {
+		"de": "Der Fragebogen wurde zwischenzeitlich geändert - etwa in einem anderen Fenster. Ihre Angaben auf dieser Seite wurden übernommen; bitte prüfen Sie sie, bevor Sie fortfahren.",
+		"en": "The questionnaire was changed meanwhile - for instance in another window. Your entries on this page were kept; please review them before you continue.",
+		"es": "El cuestionario fue modificado mientras tanto, por ejemplo en otra ventana. Sus respuestas en esta página se han conservado; revíselas antes de continuar.",
+		"fr": "Le questionnaire a été modifié entre-temps, par exemple dans une autre fenêtre. Vos réponses sur cette page ont été conservées ; veuillez les vérifier avant de continuer.",
+		"it": "Il questionario è stato modificato nel frattempo, ad esempio in un'altra finestra. Le sue risposte su questa pagina sono state mantenute; la preghiamo di verificarle prima di continuare.",
+		"pl": "Kwestionariusz został w międzyczasie zmieniony - na przykład w innym oknie. Twoje odpowiedzi na tej stronie zostały zachowane; sprawdź je przed kontynuowaniem.",
+	},
 }
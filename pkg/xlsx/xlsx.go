@@ -0,0 +1,337 @@
+// Package xlsx writes minimal Office Open XML spreadsheets;
+// one or more sheets of string or numeric cells,
+// optionally with frozen header rows.
+//
+// It has no dependencies outside the standard library;
+// there is no reading, no formulas and no shared strings table -
+// strings are written inline.
+//
+// Purpose: partners opening our CSV exports in Excel
+// struggle with delimiters, UTF-8 and German decimal commas;
+// an XLSX file has none of these problems.
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// CellT is a single spreadsheet cell - either string or number
+type CellT struct {
+	Str   string
+	Num   float64
+	IsNum bool
+	Bold  bool
+}
+
+// Str returns a string cell
+func Str(s string) CellT {
+	return CellT{Str: s}
+}
+
+// Num returns a numeric cell
+func Num(f float64) CellT {
+	return CellT{Num: f, IsNum: true}
+}
+
+// Auto returns a numeric cell, if s can be parsed as float;
+// otherwise a string cell
+func Auto(s string) CellT {
+	s2 := strings.TrimSpace(s)
+	if s2 == "" {
+		return Str(s)
+	}
+	fl, err := strconv.ParseFloat(s2, 64)
+	if err != nil {
+		return Str(s)
+	}
+	return Num(fl)
+}
+
+// SheetT is one worksheet
+type SheetT struct {
+	Name string
+	Rows [][]CellT
+
+	FrozenRows int // number of header rows, which remain visible on scrolling
+	FrozenCols int // number of leading columns, which remain visible on scrolling
+
+	ColWidths map[int]float64 // zero-based column index => width in characters
+}
+
+// AddRow appends a row of cells
+func (sh *SheetT) AddRow(cells ...CellT) {
+	sh.Rows = append(sh.Rows, cells)
+}
+
+// AddStrings appends a row of string cells
+func (sh *SheetT) AddStrings(vals ...string) {
+	row := make([]CellT, 0, len(vals))
+	for _, v := range vals {
+		row = append(row, Str(v))
+	}
+	sh.Rows = append(sh.Rows, row)
+}
+
+// BoldRow sets all cells in row idx to bold
+func (sh *SheetT) BoldRow(idx int) {
+	if idx < 0 || idx > len(sh.Rows)-1 {
+		return
+	}
+	for i := range sh.Rows[idx] {
+		sh.Rows[idx][i].Bold = true
+	}
+}
+
+// WorkbookT contains the sheets
+type WorkbookT struct {
+	Sheets []*SheetT
+}
+
+// New returns an empty workbook
+func New() *WorkbookT {
+	return &WorkbookT{}
+}
+
+// AddSheet creates a new sheet
+// and adds it to the workbook
+func (wb *WorkbookT) AddSheet(name string) *SheetT {
+	sh := &SheetT{Name: sheetName(name, len(wb.Sheets)+1)}
+	wb.Sheets = append(wb.Sheets, sh)
+	return sh
+}
+
+// sheet names are restricted to 31 chars
+// and must not contain any of []:*?/\
+var sheetNameCleanse = strings.NewReplacer(
+	"[", "(",
+	"]", ")",
+	":", "-",
+	"*", "-",
+	"?", "-",
+	"/", "-",
+	"\\", "-",
+)
+
+func sheetName(s string, idx int) string {
+	s = sheetNameCleanse.Replace(strings.TrimSpace(s))
+	if s == "" {
+		s = fmt.Sprintf("Sheet%v", idx)
+	}
+	if r := []rune(s); len(r) > 31 {
+		s = string(r[:31])
+	}
+	return s
+}
+
+// ColName converts a zero-based column index into A, B, ... Z, AA, AB ...
+func ColName(idx int) string {
+	name := ""
+	for idx >= 0 {
+		name = string(rune('A'+idx%26)) + name
+		idx = idx/26 - 1
+	}
+	return name
+}
+
+// Write serializes the workbook into w
+func (wb *WorkbookT) Write(w io.Writer) error {
+
+	if len(wb.Sheets) == 0 {
+		wb.AddSheet("")
+	}
+
+	zw := zip.NewWriter(w)
+
+	parts := []struct {
+		name string
+		body string
+	}{
+		{"[Content_Types].xml", wb.contentTypes()},
+		{"_rels/.rels", rootRels},
+		{"xl/workbook.xml", wb.workbook()},
+		{"xl/_rels/workbook.xml.rels", wb.workbookRels()},
+		{"xl/styles.xml", styles},
+	}
+	for _, p := range parts {
+		f, err := zw.Create(p.name)
+		if err != nil {
+			return fmt.Errorf("could not create %v: %w", p.name, err)
+		}
+		if _, err := io.WriteString(f, p.body); err != nil {
+			return fmt.Errorf("could not write %v: %w", p.name, err)
+		}
+	}
+
+	for idx, sh := range wb.Sheets {
+		name := fmt.Sprintf("xl/worksheets/sheet%v.xml", idx+1)
+		f, err := zw.Create(name)
+		if err != nil {
+			return fmt.Errorf("could not create %v: %w", name, err)
+		}
+		if err := sh.write(f); err != nil {
+			return fmt.Errorf("could not write %v: %w", name, err)
+		}
+	}
+
+	return zw.Close()
+}
+
+// Bytes is a convenience wrapper around Write
+func (wb *WorkbookT) Bytes() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	err := wb.Write(buf)
+	return buf.Bytes(), err
+}
+
+const xmlHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+
+const rootRels = xmlHeader + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+
+// cellXfs index 0 is regular, index 1 is bold;
+// wrapped text for both, so that newlines in labels show up
+const styles = xmlHeader + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>
+<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>
+<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>
+<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>
+<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0" applyAlignment="1"><alignment wrapText="1"/></xf><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1" applyAlignment="1"><alignment wrapText="1"/></xf></cellXfs>
+<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>
+</styleSheet>`
+
+func (wb *WorkbookT) contentTypes() string {
+	w := &strings.Builder{}
+	fmt.Fprint(w, xmlHeader)
+	fmt.Fprint(w, `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`+"\n")
+	fmt.Fprint(w, `<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`+"\n")
+	fmt.Fprint(w, `<Default Extension="xml" ContentType="application/xml"/>`+"\n")
+	fmt.Fprint(w, `<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`+"\n")
+	fmt.Fprint(w, `<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`+"\n")
+	for idx := range wb.Sheets {
+		fmt.Fprintf(w, `<Override PartName="/xl/worksheets/sheet%v.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`+"\n", idx+1)
+	}
+	fmt.Fprint(w, `</Types>`)
+	return w.String()
+}
+
+func (wb *WorkbookT) workbook() string {
+	w := &strings.Builder{}
+	fmt.Fprint(w, xmlHeader)
+	fmt.Fprint(w, `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">`+"\n")
+	fmt.Fprint(w, "<sheets>\n")
+	for idx, sh := range wb.Sheets {
+		fmt.Fprintf(w, `<sheet name="%v" sheetId="%v" r:id="rId%v"/>`+"\n", escape(sh.Name), idx+1, idx+1)
+	}
+	fmt.Fprint(w, "</sheets>\n")
+	fmt.Fprint(w, `</workbook>`)
+	return w.String()
+}
+
+func (wb *WorkbookT) workbookRels() string {
+	w := &strings.Builder{}
+	fmt.Fprint(w, xmlHeader)
+	fmt.Fprint(w, `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`+"\n")
+	for idx := range wb.Sheets {
+		fmt.Fprintf(w, `<Relationship Id="rId%v" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%v.xml"/>`+"\n", idx+1, idx+1)
+	}
+	// styles come after the sheets
+	fmt.Fprintf(w, `<Relationship Id="rId%v" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`+"\n", len(wb.Sheets)+1)
+	fmt.Fprint(w, `</Relationships>`)
+	return w.String()
+}
+
+// write streams the sheet XML;
+// rows are not buffered as a whole
+func (sh *SheetT) write(w io.Writer) error {
+
+	fmt.Fprint(w, xmlHeader)
+	fmt.Fprint(w, `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">`+"\n")
+
+	if sh.FrozenRows > 0 || sh.FrozenCols > 0 {
+		topLeft := fmt.Sprintf("%v%v", ColName(sh.FrozenCols), sh.FrozenRows+1)
+		pane := "bottomLeft"
+		if sh.FrozenCols > 0 && sh.FrozenRows > 0 {
+			pane = "bottomRight"
+		} else if sh.FrozenCols > 0 {
+			pane = "topRight"
+		}
+		split := ""
+		if sh.FrozenCols > 0 {
+			split += fmt.Sprintf(` xSplit="%v"`, sh.FrozenCols)
+		}
+		if sh.FrozenRows > 0 {
+			split += fmt.Sprintf(` ySplit="%v"`, sh.FrozenRows)
+		}
+		fmt.Fprintf(w,
+			`<sheetViews><sheetView workbookViewId="0"><pane%v topLeftCell="%v" activePane="%v" state="frozen"/></sheetView></sheetViews>`+"\n",
+			split, topLeft, pane,
+		)
+	}
+
+	if len(sh.ColWidths) > 0 {
+		maxCol := 0
+		for colIdx := range sh.ColWidths {
+			if colIdx > maxCol {
+				maxCol = colIdx
+			}
+		}
+		fmt.Fprint(w, "<cols>")
+		for colIdx := 0; colIdx <= maxCol; colIdx++ {
+			if wd, ok := sh.ColWidths[colIdx]; ok {
+				fmt.Fprintf(w, `<col min="%v" max="%v" width="%.1f" customWidth="1"/>`, colIdx+1, colIdx+1, wd)
+			}
+		}
+		fmt.Fprint(w, "</cols>\n")
+	}
+
+	fmt.Fprint(w, "<sheetData>\n")
+	for rowIdx, row := range sh.Rows {
+		fmt.Fprintf(w, `<row r="%v">`, rowIdx+1)
+		for colIdx, c := range row {
+			ref := fmt.Sprintf("%v%v", ColName(colIdx), rowIdx+1)
+			style := ""
+			if c.Bold {
+				style = ` s="1"`
+			}
+			if c.IsNum {
+				fmt.Fprintf(w, `<c r="%v"%v><v>%v</v></c>`, ref, style, strconv.FormatFloat(c.Num, 'f', -1, 64))
+				continue
+			}
+			if c.Str == "" && !c.Bold {
+				continue // sparse
+			}
+			fmt.Fprintf(w, `<c r="%v" t="inlineStr"%v><is><t xml:space="preserve">%v</t></is></c>`, ref, style, escape(c.Str))
+		}
+		if _, err := fmt.Fprint(w, "</row>\n"); err != nil {
+			return err
+		}
+	}
+	fmt.Fprint(w, "</sheetData>\n")
+
+	_, err := fmt.Fprint(w, `</worksheet>`)
+	return err
+}
+
+// escape XML special characters;
+// control characters other than tab and newline are invalid in XML 1.0 and are dropped
+func escape(s string) string {
+	s = strings.Map(
+		func(r rune) rune {
+			if r < 0x20 && r != '\t' && r != '\n' && r != '\r' {
+				return -1
+			}
+			return r
+		},
+		s,
+	)
+	buf := &bytes.Buffer{}
+	xml.EscapeText(buf, []byte(s))
+	return buf.String()
+}
@@ -0,0 +1,88 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestColName(t *testing.T) {
+	tests := []struct {
+		idx  int
+		want string
+	}{
+		{0, "A"},
+		{25, "Z"},
+		{26, "AA"},
+		{27, "AB"},
+		{701, "ZZ"},
+		{702, "AAA"},
+	}
+	for _, tt := range tests {
+		if got := ColName(tt.idx); got != tt.want {
+			t.Errorf("ColName(%v) = %v; want %v", tt.idx, got, tt.want)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+
+	wb := New()
+	sh := wb.AddSheet("data/with:invalid*chars")
+	sh.FrozenRows = 1
+	sh.AddStrings("user_id", "q1")
+	sh.BoldRow(0)
+	sh.AddRow(Auto("17"), Str("a < b & \x01c"))
+
+	bts, err := wb.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(bts), int64(len(bts)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(rc)
+		rc.Close()
+		parts[f.Name] = string(b)
+	}
+
+	for _, nm := range []string{
+		"[Content_Types].xml",
+		"_rels/.rels",
+		"xl/workbook.xml",
+		"xl/_rels/workbook.xml.rels",
+		"xl/styles.xml",
+		"xl/worksheets/sheet1.xml",
+	} {
+		if _, ok := parts[nm]; !ok {
+			t.Errorf("missing part %v", nm)
+		}
+	}
+
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	wants := []string{
+		`ySplit="1" topLeftCell="A2"`,
+		`<c r="A2"><v>17</v></c>`,
+		`a &lt; b &amp; c`,
+		`s="1"`,
+	}
+	for _, want := range wants {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("sheet XML does not contain %q\n%v", want, sheet)
+		}
+	}
+
+	if !strings.Contains(parts["xl/workbook.xml"], `name="data-with-invalid-chars"`) {
+		t.Errorf("sheet name not sanitized: %v", parts["xl/workbook.xml"])
+	}
+}